@@ -0,0 +1,44 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"time"
+)
+
+// Location and telemetry for a car at a fixed rate, interpolated from the nearest samples
+type CarSample struct {
+	// Always UTC
+	Timestamp    time.Time
+	SessionTime  time.Duration
+	LeaderLap    int
+	DriverNumber int
+
+	X float64
+	Y float64
+	Z float64
+
+	LapDistance float64
+	LapFraction float64
+
+	RPM      int16
+	Speed    float32
+	Gear     byte
+	Throttle float32
+	Brake    float32
+	DRS      bool
+	DRSState CarDRSState
+}
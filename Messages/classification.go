@@ -0,0 +1,88 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"fmt"
+	"time"
+)
+
+type ClassificationStatus int
+
+const (
+	ClassifiedFinished ClassificationStatus = iota
+	ClassifiedLapped
+	ClassifiedDNF
+	ClassifiedDNS
+	ClassifiedDSQ
+)
+
+func (c ClassificationStatus) String() string {
+	return [...]string{"Finished", "Lapped", "DNF", "DNS", "DSQ"}[c]
+}
+
+type ClassificationEntry struct {
+	Position  int
+	Number    int
+	Name      string
+	ShortName string
+	Team      string
+	HexColor  string
+
+	Laps int
+	// Sum of all the lap times, only set when we have a time for every lap
+	TotalTime time.Duration
+	// Gap to the winner
+	Gap Gap
+
+	Status   ClassificationStatus
+	LapsDown int
+
+	FastestLap        time.Duration
+	OverallFastestLap bool
+
+	TimePenaltySeconds  int
+	TrackLimitsWarnings int
+
+	BlueFlags          int
+	BlackAndWhiteFlags int
+
+	// Every incident the stewards looked at involving the driver and the penalties they were given
+	Penalties PenaltySummary
+}
+
+// Status as shown on the official results: Finished, +1 Lap, DNF...
+func (c ClassificationEntry) StatusText() string {
+	if c.Status != ClassifiedLapped {
+		return c.Status.String()
+	}
+
+	if c.LapsDown == 1 {
+		return "+1 Lap"
+	}
+	return fmt.Sprintf("+%d Laps", c.LapsDown)
+}
+
+type Classification struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	// Finished is the provisional result and Finalised is once the stewards are done
+	Status SessionState
+
+	Entries []ClassificationEntry
+}
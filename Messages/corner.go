@@ -0,0 +1,50 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"time"
+)
+
+type Corner struct {
+	// In order around the track of every corner found in the session so the same corner has the same number on
+	// every lap and for every driver
+	Number int
+
+	// Metres from the start/finish line. Where they lifted for corners taken without braking.
+	BrakingStart float64
+	Apex         float64
+	Exit         float64
+
+	EntrySpeed   float32
+	MinimumSpeed float32
+	ExitSpeed    float32
+	// Gear at the apex
+	Gear byte
+
+	// From braking or lifting until back on full throttle
+	Time time.Duration
+}
+
+type LapCorners struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	DriverNumber int
+	Lap          int
+	Corners      []Corner
+}
@@ -7,16 +7,31 @@ import (
 
 type DriverInfo struct {
 	StartPosition int
-	Name          string
-	ShortName     string
-	Number        int
-	Team          string
-	HexColor      string
-	Color         color.RGBA
+	// Line for the driver in the driver list when the list was sent, the list isn't resent when only this changes
+	Line      int
+	Name      string
+	ShortName string
+	Number    int
+	Team      string
+	HexColor  string
+	Color     color.RGBA
+
+	FirstName     string
+	LastName      string
+	BroadcastName string
+	CountryCode   string
+	// Unique reference for the driver that stays the same across seasons
+	Reference string
+
+	HeadshotUrl string
+	// Image data for the headshot, only populated when DriverHeadshots data is requested
+	Headshot []byte
 }
 
 type Drivers struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	Drivers []DriverInfo
 }
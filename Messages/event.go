@@ -127,11 +127,18 @@ func (o OvertakeState) String() string {
 
 const MaxSegments = 40
 
+const MaxMarshalSectors = 40
+
 type Event struct {
 	Timestamp time.Time
+	// Time since the session started (or was due to start), negative before the start
+	SessionTime time.Duration
+	// Lap the leader was on
+	LeaderLap int
 
-	Name string
-	Type EventType
+	Name             string
+	Type             EventType
+	SprintQualifying bool
 
 	Status    SessionState
 	Heartbeat bool
@@ -142,7 +149,11 @@ type Event struct {
 	Sector2Segments int
 	Sector3Segments int
 	TotalSegments   int
-	SegmentFlags    [MaxSegments]FlagState
+	// Flags for the timing segments, a marshal sector is shown on the segments it covers on the track map or on the
+	// segment with the same number when there isn't a map
+	SegmentFlags [MaxSegments]FlagState
+	// Flags for each marshal sector indexed by the sector number race control uses
+	MarshalSectorFlags [MaxMarshalSectors]FlagState
 
 	PitExitOpen bool
 	TrackStatus FlagState
@@ -20,7 +20,9 @@ import (
 )
 
 type EventTime struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	Remaining time.Duration
 }
@@ -0,0 +1,43 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"time"
+)
+
+type CarInterval struct {
+	DriverNumber int
+	// Order on track by distance covered
+	Position int
+	Laps     int
+	// Metres covered in the session
+	Distance float64
+	InPit    bool
+
+	// When the car ahead or the leader was at the same point on track, 0 if not known
+	GapToCarAhead time.Duration
+	GapToLeader   time.Duration
+	LapsDown      int
+}
+
+type Intervals struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	Cars []CarInterval
+}
@@ -0,0 +1,48 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"time"
+)
+
+type Lap struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	DriverNumber int
+	Lap          int
+	LapTime      time.Duration
+
+	Sector1 time.Duration
+	Sector2 time.Duration
+	Sector3 time.Duration
+	Segment [MaxSegments]SegmentType
+
+	Tire       TireType
+	LapsOnTire int
+
+	PitIn  bool
+	PitOut bool
+
+	// Worst track conditions seen at any point during the lap
+	TrackStatus FlagState
+	SafetyCar   TrackState
+
+	// Position when the lap was completed
+	Position int
+}
@@ -19,11 +19,41 @@ import (
 	"time"
 )
 
+type PositionStatus int
+
+const (
+	PositionUnknown PositionStatus = iota
+	PositionOnTrack
+	PositionOffTrack
+	PositionNoFix
+)
+
+func (p PositionStatus) String() string {
+	return [...]string{"Unknown", "On Track", "Off Track", "No Fix"}[p]
+}
+
 type Location struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	DriverNumber int
 	X            float64
 	Y            float64
 	Z            float64
+	// Metres around the lap from the start/finish line and how much of the lap that is, 0 until the track outline
+	// is known
+	LapDistance float64
+	LapFraction float64
+	// NoFix is sent once when a car that had a location stops reporting one, X, Y and Z will be 0
+	Status PositionStatus
+}
+
+// All of the car locations for a single timestamp
+type LocationFrame struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	Locations []Location
 }
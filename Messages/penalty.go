@@ -0,0 +1,97 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import "time"
+
+type IncidentState int
+
+const (
+	IncidentNoted IncidentState = iota
+	IncidentUnderInvestigation
+	IncidentDecided
+	IncidentServed
+)
+
+func (i IncidentState) String() string {
+	return [...]string{"Noted", "Under Investigation", "Decision", "Served"}[i]
+}
+
+type PenaltyType int
+
+const (
+	NoPenalty PenaltyType = iota
+	NoFurtherAction
+	TimePenalty
+	DriveThroughPenalty
+	StopGoPenalty
+	GridPenalty
+	Reprimand
+	BlackAndWhiteFlagPenalty
+)
+
+func (p PenaltyType) String() string {
+	return [...]string{"None", "No Further Action", "Time Penalty", "Drive Through", "Stop/Go", "Grid Penalty", "Reprimand", "Black and White Flag"}[p]
+}
+
+// An incident the stewards are dealing with, sent every time the state of it changes
+type Incident struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	// Unique for the session so updates to the same incident can be matched up
+	Id    int
+	State IncidentState
+	Lap   int
+
+	// What happened and where ("TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER)") and why ("CAUSING A COLLISION")
+	Description string
+	Reason      string
+
+	// All the cars involved and the car the decision is for
+	Cars      []int
+	Penalised int
+
+	Penalty PenaltyType
+	// Seconds for time and stop/go penalties
+	Seconds int
+	// Places for grid penalties at the next race
+	GridPlaces int
+
+	// All the race control messages for the incident in order
+	Messages []string
+}
+
+type PenaltySummary struct {
+	DriverNumber int
+
+	// Time penalties not yet served and served during the session
+	TimePenaltySeconds       int
+	ServedTimePenaltySeconds int
+
+	DriveThroughs      int
+	StopGos            int
+	GridPlaces         int
+	Reprimands         int
+	BlackAndWhiteFlags int
+	NoFurtherActions   int
+
+	// Incidents that haven't had a decision yet
+	UnderInvestigation int
+
+	Incidents []Incident
+}
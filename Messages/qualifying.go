@@ -0,0 +1,49 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import (
+	"time"
+)
+
+const MaxQualifyingParts = 3
+
+type QualifyingResult struct {
+	Position  int
+	Number    int
+	Name      string
+	ShortName string
+	Team      string
+
+	// Best lap in each part: Q1, Q2 and Q3 or SQ1, SQ2 and SQ3 for sprint qualifying
+	Times [MaxQualifyingParts]time.Duration
+
+	// Part the driver was knocked out in and the order they were knocked out, both are 0 if not knocked out
+	KnockedOutInPart int
+	EliminationOrder int
+}
+
+type QualifyingClassification struct {
+	Timestamp time.Time
+
+	SprintQualifying bool
+	Part             int
+
+	// Time needed in the current part to get through to the next part
+	CutOffTime time.Duration
+
+	Results []QualifyingResult
+}
@@ -19,9 +19,76 @@ import (
 	"time"
 )
 
+type RaceControlCategory int
+
+const (
+	OtherCategory RaceControlCategory = iota
+	FlagCategory
+	SafetyCarCategory
+	DrsCategory
+	CarEventCategory
+)
+
+func (r RaceControlCategory) String() string {
+	return [...]string{"Other", "Flag", "Safety Car", "DRS", "Car Event"}[r]
+}
+
+type RaceControlScope int
+
+const (
+	NoScope RaceControlScope = iota
+	TrackScope
+	SectorScope
+	DriverScope
+)
+
+func (r RaceControlScope) String() string {
+	return [...]string{"None", "Track", "Sector", "Driver"}[r]
+}
+
+type RaceControlStatus int
+
+const (
+	NoStatus RaceControlStatus = iota
+	EnabledStatus
+	DisabledStatus
+	DeployedStatus
+	EndingStatus
+	InThisLapStatus
+)
+
+func (r RaceControlStatus) String() string {
+	return [...]string{"None", "Enabled", "Disabled", "Deployed", "Ending", "In This Lap"}[r]
+}
+
+type RaceControlMode int
+
+const (
+	NoMode RaceControlMode = iota
+	SafetyCarMode
+	VirtualSafetyCarMode
+)
+
+func (r RaceControlMode) String() string {
+	return [...]string{"None", "Safety Car", "Virtual Safety Car"}[r]
+}
+
 type RaceControlMessage struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	Msg  string
 	Flag FlagState
+
+	Category RaceControlCategory
+	Scope    RaceControlScope
+	// Sector number as given by race control, 0 when the message isn't for a sector
+	Sector int
+	// Car the message is about, 0 when it isn't about a car
+	RacingNumber int
+	Lap          int
+	// Used for DRS and safety car messages
+	Status RaceControlStatus
+	Mode   RaceControlMode
 }
@@ -20,7 +20,9 @@ import (
 )
 
 type Radio struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	Driver string
 	Msg    []byte
@@ -0,0 +1,33 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import "time"
+
+// A change to the flag shown for a marshal sector
+type SectorFlag struct {
+	Timestamp time.Time
+
+	// Marshal sector number race control used and the timing segments it covers
+	Sector   int
+	Segments []int
+
+	Flag FlagState
+	Lap  int
+
+	// The flag was removed because it wasn't renewed or cleared in time, or the session state changed
+	Expired bool
+}
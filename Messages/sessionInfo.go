@@ -0,0 +1,58 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package Messages
+
+import "time"
+
+type Country struct {
+	Key  int
+	Code string
+	Name string
+}
+
+type Circuit struct {
+	Key       int
+	ShortName string
+}
+
+type Meeting struct {
+	Key          int
+	Name         string
+	OfficialName string
+	Location     string
+	Country      Country
+	Circuit      Circuit
+}
+
+type SessionInfo struct {
+	Timestamp time.Time
+
+	Key  int
+	Name string
+	Type SessionType
+
+	// Start and end of the session in UTC
+	StartDate time.Time
+	EndDate   time.Time
+	// Offset of the circuits local time from UTC
+	GmtOffset time.Duration
+	Timezone  *time.Location
+
+	Path          string
+	ArchiveStatus string
+
+	Meeting Meeting
+}
@@ -19,8 +19,43 @@ import (
 	"time"
 )
 
+// What a car data channel id is used for
+type TelemetryChannel int
+
+const (
+	UnknownChannel TelemetryChannel = iota
+	RPMChannel
+	SpeedChannel
+	GearChannel
+	ThrottleChannel
+	BrakeChannel
+	DRSChannel
+)
+
+func (t TelemetryChannel) String() string {
+	return [...]string{"Unknown", "RPM", "Speed", "Gear", "Throttle", "Brake", "DRS"}[t]
+}
+
+type CarDRSState int
+
+const (
+	CarDRSUnknown CarDRSState = iota
+	CarDRSOff
+	// Within a second of the car ahead and will be able to open DRS in the next zone
+	CarDRSAvailable
+	// In a DRS zone and allowed to open it but not open yet
+	CarDRSArmed
+	CarDRSOpen
+)
+
+func (c CarDRSState) String() string {
+	return [...]string{"Unknown", "Off", "Available", "Armed", "Open"}[c]
+}
+
 type Telemetry struct {
 	Timestamp    time.Time
+	SessionTime  time.Duration
+	LeaderLap    int
 	DriverNumber int
 
 	RPM      int16
@@ -28,5 +63,27 @@ type Telemetry struct {
 	Gear     byte
 	Throttle float32
 	Brake    float32
-	DRS      bool
+	// Armed or open, use DRSState to tell them apart
+	DRS bool
+
+	DRSState CarDRSState
+	// Value of the DRS channel as sent by the car
+	DRSRaw int
+
+	// Estimated from the last location and the speed
+	LapDistance float64
+	LapFraction float64
+
+	// Channels sent for the car that we don't know about yet by channel id, nil when
+	// there are none. Known channels are only in their fields above.
+	Channels map[int]float64
+}
+
+// All of the car telemetry for a single timestamp
+type TelemetryFrame struct {
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
+
+	Telemetry []Telemetry
 }
@@ -16,6 +16,7 @@
 package Messages
 
 import (
+	"fmt"
 	"image/color"
 	"time"
 )
@@ -71,6 +72,83 @@ const (
 	Mystery3 // ??? 2020 - Italy Race
 )
 
+type GapType int
+
+const (
+	NoGap GapType = iota
+	TimeGap
+	LapsGap
+	LeaderLapGap
+)
+
+func (g GapType) String() string {
+	return [...]string{"None", "Time", "Laps", "Leader Lap"}[g]
+}
+
+type Gap struct {
+	Type GapType
+	Time time.Duration
+	// Number of laps down for a LapsGap or the lap the leader is on for a LeaderLapGap
+	Laps int
+}
+
+func (g Gap) String() string {
+	switch g.Type {
+	case TimeGap:
+		return fmt.Sprintf("+%.3f", g.Time.Seconds())
+	case LapsGap:
+		return fmt.Sprintf("%d L", g.Laps)
+	case LeaderLapGap:
+		return fmt.Sprintf("LAP %d", g.Laps)
+	default:
+		return ""
+	}
+}
+
+type SpeedTrapLocation int
+
+const (
+	Intermediate1Trap SpeedTrapLocation = iota
+	Intermediate2Trap
+	FinishLineTrap
+	StraightTrap
+)
+
+func (s SpeedTrapLocation) String() string {
+	return [...]string{"I1", "I2", "FL", "ST"}[s]
+}
+
+const MaxSpeedTraps = 4
+
+type TrapSpeed struct {
+	Speed           int // KM/hr
+	PersonalFastest bool
+	OverallFastest  bool
+
+	// Fastest speed for the driver through the trap this session and where that ranks against everyone else
+	SessionBest         int // KM/hr
+	SessionBestPosition int
+}
+
+type Stint struct {
+	Compound        TireType
+	New             bool
+	TyresNotChanged bool
+
+	// First and last lap of the session driven in the stint, EndLap is 0 until a lap has been completed
+	StartLap int
+	EndLap   int
+
+	// Laps already on the tire when the stint started and the total including the laps from this stint
+	StartLaps int
+	TotalLaps int
+
+	// Raw value from the feed, the meaning of the flags isn't known and it has only been seen as 0
+	LapFlags int
+
+	LapTimes []time.Duration
+}
+
 type PitStop struct {
 	Lap          int
 	PitlaneEntry time.Time
@@ -79,7 +157,9 @@ type PitStop struct {
 }
 
 type Timing struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	Position int
 
@@ -90,9 +170,9 @@ type Timing struct {
 	HexColor  string
 	Color     color.RGBA
 
-	TimeDiffToFastest       time.Duration
-	TimeDiffToPositionAhead time.Duration
-	GapToLeader             time.Duration
+	TimeDiffToFastest       Gap
+	TimeDiffToPositionAhead Gap
+	GapToLeader             Gap
 
 	PreviousSegmentIndex   int
 	Segment                [MaxSegments]SegmentType
@@ -113,12 +193,17 @@ type Timing struct {
 	OverallFastestLap bool
 
 	KnockedOutOfQualifying bool
+	QualifyingTimes        [MaxQualifyingParts]time.Duration
+	KnockedOutInPart       int
+	EliminationOrder       int
 	ChequeredFlag          bool
 
 	Tire       TireType
 	LapsOnTire int
 	Lap        int
+	Stints     []Stint
 
+	// DRS is armed or open
 	DRSOpen bool
 
 	Pitstops     int
@@ -126,10 +211,28 @@ type Timing struct {
 
 	Location CarLocation
 
+	// Car state flags as given by the timing feed
+	InPit   bool
+	PitOut  bool
+	Retired bool
+	Stopped bool
+	// Raw status bitfield for the car. This isn't decoded because the meaning of the bits isn't documented, the
+	// values seen so far are 96, 288, 576, 608, 768 and 800 (bits 32, 64, 256 and 512)
+	Status int
+
 	SpeedTrap                int
 	SpeedTrapPersonalFastest bool
 	SpeedTrapOverallFastest  bool
+	Speeds                   [MaxSpeedTraps]TrapSpeed
 
 	TrackLimitsWarnings int
 	TimePenaltySeconds  int
+
+	// Blue flags are only active for a short time after being shown, black and white flags stay for the session
+	BlueFlag              bool
+	BlueFlagTime          time.Time
+	BlueFlags             int
+	BlackAndWhiteFlag     bool
+	BlackAndWhiteFlagTime time.Time
+	BlackAndWhiteFlags    int
 }
@@ -20,7 +20,9 @@ import (
 )
 
 type Weather struct {
-	Timestamp time.Time
+	Timestamp   time.Time
+	SessionTime time.Duration
+	LeaderLap   int
 
 	AirTemp       float64
 	Humidity      float64
@@ -0,0 +1,104 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package analysis
+
+import (
+	"time"
+)
+
+const DefaultComparisonStep = 5.0
+
+// Both laps at the same distance around the lap
+type ComparisonPoint struct {
+	Distance float64
+
+	// Positive when the second lap is slower to this point
+	Delta      time.Duration
+	SpeedDelta float32
+
+	First  Sample
+	Second Sample
+}
+
+// Where the driver started braking for a corner
+type BrakingPoint struct {
+	Distance float64
+	Speed    float32
+}
+
+type Comparison struct {
+	First  Lap
+	Second Lap
+
+	Points []ComparisonPoint
+
+	FirstBrakingPoints  []BrakingPoint
+	SecondBrakingPoints []BrakingPoint
+}
+
+// Line the two laps up by distance every step metres
+func CompareLaps(first Lap, second Lap, step float64) Comparison {
+	if step <= 0 {
+		step = DefaultComparisonStep
+	}
+
+	result := Comparison{
+		First:               first,
+		Second:              second,
+		Points:              make([]ComparisonPoint, 0),
+		FirstBrakingPoints:  brakingPoints(first),
+		SecondBrakingPoints: brakingPoints(second),
+	}
+
+	if len(first.Samples) == 0 || len(second.Samples) == 0 {
+		return result
+	}
+
+	start := max(first.Samples[0].Distance, second.Samples[0].Distance)
+	end := min(first.Samples[len(first.Samples)-1].Distance, second.Samples[len(second.Samples)-1].Distance)
+
+	for distance := start; distance <= end; distance += step {
+		firstSample, firstOk := first.At(distance)
+		secondSample, secondOk := second.At(distance)
+		if !firstOk || !secondOk {
+			continue
+		}
+
+		result.Points = append(result.Points, ComparisonPoint{
+			Distance:   distance,
+			Delta:      secondSample.Time - firstSample.Time,
+			SpeedDelta: secondSample.Speed - firstSample.Speed,
+			First:      firstSample,
+			Second:     secondSample,
+		})
+	}
+
+	return result
+}
+
+func brakingPoints(lap Lap) []BrakingPoint {
+	result := make([]BrakingPoint, 0)
+
+	braking := false
+	for _, sample := range lap.Samples {
+		if sample.Brake > 0 && !braking {
+			result = append(result, BrakingPoint{Distance: sample.Distance, Speed: sample.Speed})
+		}
+		braking = sample.Brake > 0
+	}
+
+	return result
+}
@@ -0,0 +1,144 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package analysis
+
+import (
+	"math"
+	"sort"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// Throttle value that counts as back on full throttle after a corner
+const fullThrottle = 95
+
+// Braking or lifting that loses less speed than this isn't a corner
+const minCornerSpeedLoss = 10
+
+// Apexes closer than this on different laps or for different drivers are the same corner
+const sameCornerDistance = 50
+
+// Find the corners on a lap from where the driver brakes or lifts until they are back on full throttle. Corners are
+// numbered in the order they are found on the lap, use CornerNumbers to number them by where they are on the track.
+func Corners(lap Lap) []Messages.Corner {
+	result := make([]Messages.Corner, 0)
+	samples := lap.Samples
+
+	for start := 0; start < len(samples); start++ {
+		if !slowing(samples[start]) || (start > 0 && slowing(samples[start-1])) {
+			continue
+		}
+
+		// Braking again before getting back on full throttle is still the same corner
+		exit := len(samples) - 1
+		for x := start + 1; x < len(samples); x++ {
+			if !slowing(samples[x]) {
+				exit = x
+				break
+			}
+		}
+
+		// Corners taken with only a lift don't have a braking point so use where they lifted
+		braking := start
+		apex := start
+		for x := start; x <= exit; x++ {
+			if samples[braking].Brake <= 0 && samples[x].Brake > 0 {
+				braking = x
+			}
+			if samples[x].Speed < samples[apex].Speed {
+				apex = x
+			}
+		}
+
+		if samples[start].Speed-samples[apex].Speed >= minCornerSpeedLoss {
+			result = append(result, Messages.Corner{
+				Number:       len(result) + 1,
+				BrakingStart: samples[braking].Distance,
+				Apex:         samples[apex].Distance,
+				Exit:         samples[exit].Distance,
+				EntrySpeed:   samples[start].Speed,
+				MinimumSpeed: samples[apex].Speed,
+				ExitSpeed:    samples[exit].Speed,
+				Gear:         samples[apex].Gear,
+				Time:         samples[exit].Time - samples[start].Time,
+			})
+		}
+
+		start = exit
+	}
+
+	return result
+}
+
+// Braking or off full throttle
+func slowing(sample Sample) bool {
+	return sample.Brake > 0 || sample.Throttle < fullThrottle
+}
+
+// Where each corner is around the lap so a corner keeps the same number on every lap and for every driver even when
+// a lift or braking for a kink only happens on some laps
+type CornerNumbers struct {
+	// Apex of the first time each corner was found, ordered by distance
+	apexes []float64
+}
+
+// Numbers the corners by their order around the track of all the corners found so far. Returns the numbered corners
+// and true if a corner not seen before was found, which changes the numbers of any corners after it.
+func (c *CornerNumbers) Number(corners []Messages.Corner, length float64) ([]Messages.Corner, bool) {
+	matched := make([]float64, len(corners))
+	used := make(map[float64]bool)
+	added := false
+
+	for x, corner := range corners {
+		nearest := -1
+		for y, apex := range c.apexes {
+			if used[apex] || lapDistanceBetween(corner.Apex, apex, length) > sameCornerDistance {
+				continue
+			}
+			if nearest == -1 ||
+				lapDistanceBetween(corner.Apex, apex, length) < lapDistanceBetween(corner.Apex, c.apexes[nearest], length) {
+				nearest = y
+			}
+		}
+
+		if nearest == -1 {
+			c.apexes = append(c.apexes, corner.Apex)
+			sort.Float64s(c.apexes)
+			matched[x] = corner.Apex
+			added = true
+		} else {
+			matched[x] = c.apexes[nearest]
+		}
+		used[matched[x]] = true
+	}
+
+	result := make([]Messages.Corner, len(corners))
+	for x, corner := range corners {
+		corner.Number = sort.SearchFloat64s(c.apexes, matched[x]) + 1
+		result[x] = corner
+	}
+
+	return result, added
+}
+
+// Distance between two points on the lap allowing for one being just before the line and the other just after
+func lapDistanceBetween(first float64, second float64, length float64) float64 {
+	result := math.Abs(first - second)
+	if length > 0 && result > length/2 {
+		result = length - result
+	}
+	return result
+}
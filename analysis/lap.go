@@ -0,0 +1,116 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// Telemetry for a point on the lap
+type Sample struct {
+	// Since the car crossed the start/finish line
+	Time time.Duration
+	// Metres from the start/finish line
+	Distance float64
+
+	RPM      int16
+	Speed    float32
+	Gear     byte
+	Throttle float32
+	Brake    float32
+	DRS      bool
+}
+
+type Lap struct {
+	DriverNumber int
+	Lap          int
+	// Ordered by distance
+	Samples []Sample
+}
+
+// Convert the telemetry for a single lap, the times are from when the car crossed the line
+func CreateLap(driverNumber int, lap int, telemetry []Messages.Telemetry) Lap {
+	result := Lap{
+		DriverNumber: driverNumber,
+		Lap:          lap,
+		Samples:      make([]Sample, 0, len(telemetry)),
+	}
+
+	if len(telemetry) == 0 {
+		return result
+	}
+
+	// Work back from the first sample to when the car was on the line
+	first := telemetry[0]
+	lapStart := first.Timestamp
+	if first.Speed > 0 {
+		lapStart = lapStart.Add(-time.Duration(first.LapDistance / (float64(first.Speed) / 3.6) * float64(time.Second)))
+	}
+
+	for _, sample := range telemetry {
+		result.Samples = append(result.Samples, Sample{
+			Time:     sample.Timestamp.Sub(lapStart),
+			Distance: sample.LapDistance,
+			RPM:      sample.RPM,
+			Speed:    sample.Speed,
+			Gear:     sample.Gear,
+			Throttle: sample.Throttle,
+			Brake:    sample.Brake,
+			DRS:      sample.DRS,
+		})
+	}
+
+	sort.SliceStable(result.Samples, func(i, j int) bool {
+		return result.Samples[i].Distance < result.Samples[j].Distance
+	})
+
+	return result
+}
+
+// The sample at the distance interpolated between the samples either side
+func (l Lap) At(distance float64) (Sample, bool) {
+	count := len(l.Samples)
+	if count == 0 || distance < l.Samples[0].Distance || distance > l.Samples[count-1].Distance {
+		return Sample{}, false
+	}
+
+	x := sort.Search(count, func(i int) bool { return l.Samples[i].Distance >= distance })
+	after := l.Samples[x]
+	if x == 0 || after.Distance == distance {
+		return after, true
+	}
+
+	before := l.Samples[x-1]
+	fraction := (distance - before.Distance) / (after.Distance - before.Distance)
+
+	// Gears and DRS can't be in between so use the earlier sample
+	result := before
+	result.Distance = distance
+	result.Time = before.Time + time.Duration(fraction*float64(after.Time-before.Time))
+	result.RPM = int16(interpolate(float64(before.RPM), float64(after.RPM), fraction))
+	result.Speed = float32(interpolate(float64(before.Speed), float64(after.Speed), fraction))
+	result.Throttle = float32(interpolate(float64(before.Throttle), float64(after.Throttle), fraction))
+	result.Brake = float32(interpolate(float64(before.Brake), float64(after.Brake), fraction))
+
+	return result, true
+}
+
+func interpolate(before float64, after float64, fraction float64) float64 {
+	return before + (after-before)*fraction
+}
@@ -17,15 +17,20 @@ package connection
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"github.com/f1gopher/f1gopherlib/f1log"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 )
 
 type AssetStore interface {
 	TeamRadio(file string) ([]byte, error)
+	DriverHeadshot(url string) ([]byte, error)
 }
 
 type assets struct {
@@ -43,52 +48,97 @@ func CreateAssetStore(url string, cache string, log *f1log.F1GopherLibLog) Asset
 }
 
 func (a *assets) TeamRadio(file string) ([]byte, error) {
-	url := a.url + file
+	return a.fetch(a.url+file, filepath.Join("TeamRadio", file), "team radio")
+}
+
+func (a *assets) DriverHeadshot(headshotUrl string) ([]byte, error) {
+	_, err := url.Parse(headshotUrl)
+	if err != nil {
+		a.log.Errorf("Parsing driver headshot url '%s': %v", headshotUrl, err)
+		return nil, err
+	}
+
+	// Headshot urls come from the feed and the file name is the same for every driver so cache using a hash of the
+	// whole url rather than trusting the path
+	hash := sha256.Sum256([]byte(headshotUrl))
+	return a.fetch(headshotUrl, filepath.Join("Headshots", hex.EncodeToString(hash[:])), "driver headshot")
+}
 
+func (a *assets) fetch(url string, cacheFile string, name string) ([]byte, error) {
 	if len(a.cache) > 0 {
+		// File names come from the feed so don't allow anything outside of the cache
+		if !filepath.IsLocal(cacheFile) {
+			err := fmt.Errorf("cache file '%s' is outside of the cache", cacheFile)
+			a.log.Errorf("Fetching %s for '%s': %v", name, url, err)
+			return nil, err
+		}
+
 		// If file matching url doesn't exist then retrieve
-		cachedFile := filepath.Join(a.cache, "TeamRadio", file)
+		cachedFile := filepath.Join(a.cache, cacheFile)
 		cachedFile, _ = filepath.Abs(cachedFile)
 		f, err := os.Open(cachedFile)
 
 		if os.IsNotExist(err) {
-			f.Close()
-
 			var resp *http.Response
 			resp, err = http.Get(url)
 			if err != nil {
-				a.log.Errorf("Fetching team radio for '%s': %v", url, err)
+				a.log.Errorf("Fetching %s for '%s': %v", name, url, err)
 				return nil, err
 			}
 			defer resp.Body.Close()
 
-			scanner := bufio.NewScanner(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("unexpected status: %s", resp.Status)
+				a.log.Errorf("Fetching %s for '%s': %v", name, url, err)
+				return nil, err
+			}
 
 			err = os.MkdirAll(filepath.Dir(cachedFile), 0755)
+			if err != nil {
+				a.log.Errorf("Creating cache folder for %s '%s': %v", name, cachedFile, err)
+				return nil, err
+			}
 
 			// Write body to file - using url as name
 			var newFile *os.File
 			newFile, err = os.Create(cachedFile)
-			defer newFile.Close()
-			for scanner.Scan() {
-				_, err = newFile.Write(scanner.Bytes())
+			if err != nil {
+				a.log.Errorf("Creating cache file for %s '%s': %v", name, cachedFile, err)
+				return nil, err
+			}
 
-				// need newline for scanner to split
-				newFile.WriteString("\n")
+			_, err = io.Copy(newFile, resp.Body)
+			newFile.Close()
+			if err != nil {
+				a.log.Errorf("Writing cache file for %s '%s': %v", name, cachedFile, err)
+				os.Remove(cachedFile)
+				return nil, err
 			}
+
 			f, err = os.Open(cachedFile)
 		}
 
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
 		return io.ReadAll(bufio.NewReader(f))
 	}
 
 	var resp *http.Response
 	resp, err := http.Get(url)
 	if err != nil {
-		a.log.Errorf("Fetching team radio for '%s': %v", url, err)
+		a.log.Errorf("Fetching %s for '%s': %v", name, url, err)
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status: %s", resp.Status)
+		a.log.Errorf("Fetching %s for '%s': %v", name, url, err)
+		return nil, err
+	}
+
 	return io.ReadAll(bufio.NewReader(resp.Body))
 }
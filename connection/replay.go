@@ -433,7 +433,7 @@ func (r *replay) get(url string) *bufio.Scanner {
 	var resp *http.Response
 	resp, err := http.Get(url)
 	if err != nil {
-		r.log.Errorf("Replay get url '%s': %s", err)
+		r.log.Errorf("Replay get url '%s': %s", url, err)
 		return nil
 	}
 	// TODO - probably need to tidy this up but if we have no cache then we can't close it here or no data
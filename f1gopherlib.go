@@ -25,19 +25,23 @@ import (
 	"time"
 
 	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/analysis"
 	"github.com/f1gopher/f1gopherlib/connection"
 	"github.com/f1gopher/f1gopherlib/f1log"
 	"github.com/f1gopher/f1gopherlib/flowControl"
 	"github.com/f1gopher/f1gopherlib/parser"
+	"github.com/f1gopher/f1gopherlib/trackMap"
 )
 
 type F1GopherLib interface {
 	Name() string
 	Session() Messages.SessionType
 	CircuitTimezone() *time.Location
+	LocalTime(timestamp time.Time) time.Time
 	SessionStart() time.Time
 	Track() string
 	TrackYear() int
+	TrackMap() trackMap.TrackMap
 	TimeLostInPitlane() time.Duration
 
 	Weather() <-chan Messages.Weather
@@ -49,8 +53,30 @@ type F1GopherLib interface {
 	Time() <-chan Messages.EventTime
 	Radio() <-chan Messages.Radio
 	Drivers() <-chan Messages.Drivers
+	Laps() <-chan Messages.Lap
+	Classification() <-chan Messages.Classification
+	Penalties() <-chan Messages.Incident
+	LocationFrames() <-chan Messages.LocationFrame
+	TelemetryFrames() <-chan Messages.TelemetryFrame
+	CarSamples() <-chan Messages.CarSample
+	Intervals() <-chan Messages.Intervals
+	Corners() <-chan Messages.LapCorners
+
+	LapHistory(driverNumber int) []Messages.Lap
+	QualifyingResults() Messages.QualifyingClassification
+	FinalClassification() Messages.Classification
+	SessionInfo() Messages.SessionInfo
+	PenaltySummary(driverNumber int) Messages.PenaltySummary
+	SectorFlagHistory(sector int) []Messages.SectorFlag
+	LapTelemetry(driverNumber int, lap int) analysis.Lap
+	CompareLaps(firstDriver int, firstLap int, secondDriver int, secondLap int, step float64) (analysis.Comparison, error)
+	LapCorners(driverNumber int) []Messages.LapCorners
 
 	SelectTelemetrySources(drivers []int)
+	SetTelemetryPolicy(policy parser.TelemetryPolicy)
+	SetResampleInterval(interval time.Duration)
+	SetTelemetryChannels(channels map[int]Messages.TelemetryChannel)
+	SetDRSStates(states map[int]Messages.CarDRSState)
 
 	IncrementLap()
 	IncrementTime(duration time.Duration)
@@ -72,6 +98,9 @@ type f1gopherlib struct {
 	trackYear         int
 	timeLostInPitlane time.Duration
 
+	// Track map loaded from the cache
+	savedTrackMap *trackMap.TrackMap
+
 	connection   connection.Connection
 	dataHandler  *parser.Parser
 	replayTiming flowControl.Flow
@@ -85,6 +114,14 @@ type f1gopherlib struct {
 	eventTime           chan Messages.EventTime
 	radio               chan Messages.Radio
 	drivers             chan Messages.Drivers
+	laps                chan Messages.Lap
+	classification      chan Messages.Classification
+	penalties           chan Messages.Incident
+	locationFrames      chan Messages.LocationFrame
+	telemetryFrames     chan Messages.TelemetryFrame
+	carSamples          chan Messages.CarSample
+	intervals           chan Messages.Intervals
+	corners             chan Messages.LapCorners
 
 	ctxShutdown context.CancelFunc
 	ctx         context.Context
@@ -102,6 +139,14 @@ const locationChannelSize = 1000
 const eventTimeChannelSize = 10
 const radioChannelSize = 100
 const driversChannelSize = 100
+const lapsChannelSize = 1000
+const classificationChannelSize = 10
+const penaltiesChannelSize = 100
+const locationFramesChannelSize = 100
+const telemetryFramesChannelSize = 100
+const carSamplesChannelSize = 1000
+const intervalsChannelSize = 100
+const cornersChannelSize = 100
 
 var f1Log = f1log.CreateLog()
 
@@ -220,6 +265,14 @@ func CreateLive(requestedData parser.DataSource, archive string, cache string) (
 		eventTime:           make(chan Messages.EventTime, eventTimeChannelSize),
 		radio:               make(chan Messages.Radio, radioChannelSize),
 		drivers:             make(chan Messages.Drivers, driversChannelSize),
+		laps:                make(chan Messages.Lap, lapsChannelSize),
+		classification:      make(chan Messages.Classification, classificationChannelSize),
+		penalties:           make(chan Messages.Incident, penaltiesChannelSize),
+		locationFrames:      make(chan Messages.LocationFrame, locationFramesChannelSize),
+		telemetryFrames:     make(chan Messages.TelemetryFrame, telemetryFramesChannelSize),
+		carSamples:          make(chan Messages.CarSample, carSamplesChannelSize),
+		intervals:           make(chan Messages.Intervals, intervalsChannelSize),
+		corners:             make(chan Messages.LapCorners, cornersChannelSize),
 
 		archive:           archive,
 		session:           currentEvent.Type,
@@ -244,7 +297,7 @@ func CreateDebugReplay(
 	replayFile string,
 	dataFlow flowControl.FlowType) (F1GopherLib, error) {
 
-	// TODO - read event info from debug file
+	// The event isn't known up front so the name, timezone and start time come from the session info in the feed
 	event := RaceEvent{}
 
 	f1Log.Infof("Creating live replay session for: %v", event.string())
@@ -259,6 +312,14 @@ func CreateDebugReplay(
 		eventTime:           make(chan Messages.EventTime, eventTimeChannelSize),
 		radio:               make(chan Messages.Radio, radioChannelSize),
 		drivers:             make(chan Messages.Drivers, driversChannelSize),
+		laps:                make(chan Messages.Lap, lapsChannelSize),
+		classification:      make(chan Messages.Classification, classificationChannelSize),
+		penalties:           make(chan Messages.Incident, penaltiesChannelSize),
+		locationFrames:      make(chan Messages.LocationFrame, locationFramesChannelSize),
+		telemetryFrames:     make(chan Messages.TelemetryFrame, telemetryFramesChannelSize),
+		carSamples:          make(chan Messages.CarSample, carSamplesChannelSize),
+		intervals:           make(chan Messages.Intervals, intervalsChannelSize),
+		corners:             make(chan Messages.LapCorners, cornersChannelSize),
 		session:             event.Type,
 		name:                event.Name,
 		timezone:            event.Timezone(),
@@ -294,6 +355,14 @@ func CreateReplay(
 		eventTime:           make(chan Messages.EventTime, eventTimeChannelSize),
 		radio:               make(chan Messages.Radio, radioChannelSize),
 		drivers:             make(chan Messages.Drivers, driversChannelSize),
+		laps:                make(chan Messages.Lap, lapsChannelSize),
+		classification:      make(chan Messages.Classification, classificationChannelSize),
+		penalties:           make(chan Messages.Incident, penaltiesChannelSize),
+		locationFrames:      make(chan Messages.LocationFrame, locationFramesChannelSize),
+		telemetryFrames:     make(chan Messages.TelemetryFrame, telemetryFramesChannelSize),
+		carSamples:          make(chan Messages.CarSample, carSamplesChannelSize),
+		intervals:           make(chan Messages.Intervals, intervalsChannelSize),
+		corners:             make(chan Messages.LapCorners, cornersChannelSize),
 		session:             event.Type,
 		name:                event.Name,
 		timezone:            event.Timezone(),
@@ -313,6 +382,7 @@ func CreateReplay(
 
 func (f *f1gopherlib) connectLive(requestedData parser.DataSource, archiveFile string, event RaceEvent, cache string) error {
 
+	requestedData, trackMapFolder := f.loadTrackMap(requestedData, cache)
 	cache = f.cachePath(cache, event)
 
 	if len(archiveFile) == 0 {
@@ -342,7 +412,15 @@ func (f *f1gopherlib) connectLive(requestedData parser.DataSource, archiveFile s
 		f.location,
 		f.eventTime,
 		f.radio,
-		f.drivers)
+		f.drivers,
+		f.laps,
+		f.classification,
+		f.penalties,
+		f.locationFrames,
+		f.telemetryFrames,
+		f.carSamples,
+		f.intervals,
+		f.corners)
 
 	assetStore := connection.CreateAssetStore(event.Url(), cache, f1Log)
 
@@ -357,6 +435,11 @@ func (f *f1gopherlib) connectLive(requestedData parser.DataSource, archiveFile s
 		f1Log,
 		event.Timezone())
 
+	f.dataHandler.SetTrackMapStore(trackMapFolder, f.track, f.trackYear)
+	if f.savedTrackMap != nil {
+		f.dataHandler.UseTrackMap(*f.savedTrackMap)
+	}
+
 	go f.dataHandler.Process()
 	go f.replayTiming.Run()
 
@@ -388,7 +471,15 @@ func (f *f1gopherlib) connectDebugReplay(
 		f.location,
 		f.eventTime,
 		f.radio,
-		f.drivers)
+		f.drivers,
+		f.laps,
+		f.classification,
+		f.penalties,
+		f.locationFrames,
+		f.telemetryFrames,
+		f.carSamples,
+		f.intervals,
+		f.corners)
 
 	// Don't use a cache for debug replays because we don't always know the event yet to give it a useful folder name
 	assetStore := connection.CreateAssetStore(event.Url(), "", f1Log)
@@ -417,6 +508,7 @@ func (f *f1gopherlib) connectReplay(
 	dataFlow flowControl.FlowType) error {
 
 	url := event.Url()
+	requestedData, trackMapFolder := f.loadTrackMap(requestedData, cache)
 	cache = f.cachePath(cache, event)
 
 	f.connection = connection.CreateReplay(f.ctx, &f.wg, f1Log, url, event.Type, event.RaceTime.Year(), cache)
@@ -438,7 +530,15 @@ func (f *f1gopherlib) connectReplay(
 		f.location,
 		f.eventTime,
 		f.radio,
-		f.drivers)
+		f.drivers,
+		f.laps,
+		f.classification,
+		f.penalties,
+		f.locationFrames,
+		f.telemetryFrames,
+		f.carSamples,
+		f.intervals,
+		f.corners)
 
 	assetStore := connection.CreateAssetStore(event.Url(), cache, f1Log)
 
@@ -453,12 +553,47 @@ func (f *f1gopherlib) connectReplay(
 		f1Log,
 		event.Timezone())
 
+	f.dataHandler.SetTrackMapStore(trackMapFolder, f.track, f.trackYear)
+	if f.savedTrackMap != nil {
+		f.dataHandler.UseTrackMap(*f.savedTrackMap)
+	}
+
 	go f.dataHandler.Process()
 	go f.replayTiming.Run()
 
 	return nil
 }
 
+// Track maps are the same for every session at a track so are kept at the top of the cache
+func (f *f1gopherlib) loadTrackMap(requestedData parser.DataSource, cache string) (parser.DataSource, string) {
+	// Events need the marshal sectors from the map to show sector flags on the segments and everything with a lap
+	// distance needs the outline
+	needsTrackMap := requestedData&parser.TrackMap == parser.TrackMap ||
+		requestedData&parser.Event == parser.Event ||
+		requestedData&parser.Intervals == parser.Intervals ||
+		requestedData&parser.LapTelemetry == parser.LapTelemetry ||
+		requestedData&parser.Corners == parser.Corners ||
+		requestedData&parser.Location == parser.Location ||
+		requestedData&parser.LocationFrames == parser.LocationFrames ||
+		requestedData&parser.Telemetry == parser.Telemetry ||
+		requestedData&parser.TelemetryFrames == parser.TelemetryFrames ||
+		requestedData&parser.CarSamples == parser.CarSamples
+	if len(cache) == 0 || !needsTrackMap {
+		return requestedData, ""
+	}
+
+	folder := filepath.Join(cache, "TrackMaps")
+	loaded, err := trackMap.Load(folder, f.track, f.trackYear)
+	if err == nil && loaded.Complete() {
+		f.savedTrackMap = &loaded
+
+		// Don't need to build it again
+		return requestedData &^ parser.TrackMap, folder
+	}
+
+	return requestedData, folder
+}
+
 func (f *f1gopherlib) cachePath(cache string, event RaceEvent) string {
 	return filepath.Join(cache, fmt.Sprintf("%d", event.RaceTime.Year()), fmt.Sprintf("%s_%s", event.RaceTime.Format("2006-01-02"), event.Name), event.Type.String())
 }
@@ -467,18 +602,41 @@ func (f *f1gopherlib) Session() Messages.SessionType {
 	return f.session
 }
 
+// Values from the session info in the feed are used once they have been received, until then the values from the
+// event the session was created for are used
 func (f *f1gopherlib) Name() string {
+	if info := f.SessionInfo(); len(info.Meeting.Name) > 0 {
+		return info.Meeting.Name
+	}
 	return f.name
 }
 
 func (f *f1gopherlib) CircuitTimezone() *time.Location {
+	if info := f.SessionInfo(); info.Timezone != nil {
+		return info.Timezone
+	}
 	return f.timezone
 }
 
 func (f *f1gopherlib) SessionStart() time.Time {
+	if info := f.SessionInfo(); !info.StartDate.IsZero() {
+		return info.StartDate
+	}
 	return f.sessionStart
 }
 
+// Converts the UTC timestamp from any message to the local time at the circuit
+func (f *f1gopherlib) LocalTime(timestamp time.Time) time.Time {
+	return timestamp.In(f.CircuitTimezone())
+}
+
+func (f *f1gopherlib) SessionInfo() Messages.SessionInfo {
+	if f.dataHandler == nil {
+		return Messages.SessionInfo{}
+	}
+	return f.dataHandler.SessionInfo()
+}
+
 func (f *f1gopherlib) Track() string {
 	return f.track
 }
@@ -487,6 +645,17 @@ func (f *f1gopherlib) TrackYear() int {
 	return f.trackYear
 }
 
+func (f *f1gopherlib) TrackMap() trackMap.TrackMap {
+	if f.savedTrackMap != nil {
+		return *f.savedTrackMap
+	}
+
+	if f.dataHandler == nil {
+		return trackMap.TrackMap{}
+	}
+	return f.dataHandler.TrackMap()
+}
+
 func (f *f1gopherlib) TimeLostInPitlane() time.Duration {
 	return f.timeLostInPitlane
 }
@@ -527,10 +696,90 @@ func (f *f1gopherlib) Drivers() <-chan Messages.Drivers {
 	return f.drivers
 }
 
+func (f *f1gopherlib) Laps() <-chan Messages.Lap {
+	return f.laps
+}
+
+func (f *f1gopherlib) Classification() <-chan Messages.Classification {
+	return f.classification
+}
+
+func (f *f1gopherlib) Penalties() <-chan Messages.Incident {
+	return f.penalties
+}
+
+func (f *f1gopherlib) LocationFrames() <-chan Messages.LocationFrame {
+	return f.locationFrames
+}
+
+func (f *f1gopherlib) TelemetryFrames() <-chan Messages.TelemetryFrame {
+	return f.telemetryFrames
+}
+
+func (f *f1gopherlib) CarSamples() <-chan Messages.CarSample {
+	return f.carSamples
+}
+
+func (f *f1gopherlib) Intervals() <-chan Messages.Intervals {
+	return f.intervals
+}
+
+func (f *f1gopherlib) Corners() <-chan Messages.LapCorners {
+	return f.corners
+}
+
+func (f *f1gopherlib) LapHistory(driverNumber int) []Messages.Lap {
+	return f.dataHandler.LapHistory(driverNumber)
+}
+
+func (f *f1gopherlib) QualifyingResults() Messages.QualifyingClassification {
+	return f.dataHandler.QualifyingResults()
+}
+
+func (f *f1gopherlib) FinalClassification() Messages.Classification {
+	return f.dataHandler.FinalClassification()
+}
+
+func (f *f1gopherlib) PenaltySummary(driverNumber int) Messages.PenaltySummary {
+	return f.dataHandler.PenaltySummary(driverNumber)
+}
+
+func (f *f1gopherlib) SectorFlagHistory(sector int) []Messages.SectorFlag {
+	return f.dataHandler.SectorFlagHistory(sector)
+}
+
+func (f *f1gopherlib) LapTelemetry(driverNumber int, lap int) analysis.Lap {
+	return f.dataHandler.LapTelemetry(driverNumber, lap)
+}
+
+func (f *f1gopherlib) CompareLaps(firstDriver int, firstLap int, secondDriver int, secondLap int, step float64) (analysis.Comparison, error) {
+	return f.dataHandler.CompareLaps(firstDriver, firstLap, secondDriver, secondLap, step)
+}
+
+func (f *f1gopherlib) LapCorners(driverNumber int) []Messages.LapCorners {
+	return f.dataHandler.LapCorners(driverNumber)
+}
+
 func (f *f1gopherlib) SelectTelemetrySources(drivers []int) {
 	f.dataHandler.SelectTelemetrySources(drivers)
 }
 
+func (f *f1gopherlib) SetTelemetryPolicy(policy parser.TelemetryPolicy) {
+	f.dataHandler.SetTelemetryPolicy(policy)
+}
+
+func (f *f1gopherlib) SetResampleInterval(interval time.Duration) {
+	f.dataHandler.SetResampleInterval(interval)
+}
+
+func (f *f1gopherlib) SetTelemetryChannels(channels map[int]Messages.TelemetryChannel) {
+	f.dataHandler.SetTelemetryChannels(channels)
+}
+
+func (f *f1gopherlib) SetDRSStates(states map[int]Messages.CarDRSState) {
+	f.dataHandler.SetDRSStates(states)
+}
+
 func (f *f1gopherlib) IncrementLap() {
 	// Only makes sense for races
 	if f.session == Messages.RaceSession || f.session == Messages.SprintSession {
@@ -561,6 +810,7 @@ func (f *f1gopherlib) IsPaused() bool {
 func (f *f1gopherlib) Close() {
 	f.name = ""
 	f.track = ""
+	f.savedTrackMap = nil
 
 	f.ctxShutdown()
 	f.wg.Wait()
@@ -577,4 +827,12 @@ func (f *f1gopherlib) Close() {
 	close(f.eventTime)
 	close(f.radio)
 	close(f.drivers)
+	close(f.laps)
+	close(f.classification)
+	close(f.penalties)
+	close(f.locationFrames)
+	close(f.telemetryFrames)
+	close(f.carSamples)
+	close(f.intervals)
+	close(f.corners)
 }
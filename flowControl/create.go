@@ -33,6 +33,14 @@ type Flow interface {
 	AddLocation(timing Messages.Location)
 	AddRadio(timing Messages.Radio)
 	AddDrivers(driver Messages.Drivers)
+	AddLap(lap Messages.Lap)
+	AddClassification(classification Messages.Classification)
+	AddPenalty(penalty Messages.Incident)
+	AddLocationFrame(frame Messages.LocationFrame)
+	AddTelemetryFrame(frame Messages.TelemetryFrame)
+	AddCarSample(sample Messages.CarSample)
+	AddIntervals(intervals Messages.Intervals)
+	AddLapCorners(corners Messages.LapCorners)
 
 	IncrementLap()
 	IncrementTime(duration time.Duration)
@@ -60,7 +68,15 @@ func CreateFlowControl(
 	outputLocation chan<- Messages.Location,
 	outputEventTime chan<- Messages.EventTime,
 	outputRadio chan<- Messages.Radio,
-	outputDrivers chan<- Messages.Drivers) Flow {
+	outputDrivers chan<- Messages.Drivers,
+	outputLaps chan<- Messages.Lap,
+	outputClassification chan<- Messages.Classification,
+	outputPenalties chan<- Messages.Incident,
+	outputLocationFrames chan<- Messages.LocationFrame,
+	outputTelemetryFrames chan<- Messages.TelemetryFrame,
+	outputCarSamples chan<- Messages.CarSample,
+	outputIntervals chan<- Messages.Intervals,
+	outputCorners chan<- Messages.LapCorners) Flow {
 
 	switch flowType {
 	case Realtime:
@@ -76,6 +92,14 @@ func CreateFlowControl(
 			outputEventTime:           outputEventTime,
 			outputRadio:               outputRadio,
 			outputDrivers:             outputDrivers,
+			outputLaps:                outputLaps,
+			outputClassification:      outputClassification,
+			outputPenalties:           outputPenalties,
+			outputLocationFrames:      outputLocationFrames,
+			outputTelemetryFrames:     outputTelemetryFrames,
+			outputCarSamples:          outputCarSamples,
+			outputIntervals:           outputIntervals,
+			outputCorners:             outputCorners,
 		}
 
 	case StraightThrough:
@@ -89,6 +113,14 @@ func CreateFlowControl(
 			outputEventTime:           outputEventTime,
 			outputRadio:               outputRadio,
 			outputDrivers:             outputDrivers,
+			outputLaps:                outputLaps,
+			outputClassification:      outputClassification,
+			outputPenalties:           outputPenalties,
+			outputLocationFrames:      outputLocationFrames,
+			outputTelemetryFrames:     outputTelemetryFrames,
+			outputCarSamples:          outputCarSamples,
+			outputIntervals:           outputIntervals,
+			outputCorners:             outputCorners,
 		}
 
 	default:
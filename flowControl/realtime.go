@@ -32,6 +32,14 @@ type realtime struct {
 	outputEventTime           chan<- Messages.EventTime
 	outputRadio               chan<- Messages.Radio
 	outputDrivers             chan<- Messages.Drivers
+	outputLaps                chan<- Messages.Lap
+	outputClassification      chan<- Messages.Classification
+	outputPenalties           chan<- Messages.Incident
+	outputLocationFrames      chan<- Messages.LocationFrame
+	outputTelemetryFrames     chan<- Messages.TelemetryFrame
+	outputCarSamples          chan<- Messages.CarSample
+	outputIntervals           chan<- Messages.Intervals
+	outputCorners             chan<- Messages.LapCorners
 
 	weatherLock     sync.Mutex
 	weather         []Messages.Weather
@@ -49,6 +57,24 @@ type realtime struct {
 	radio           []Messages.Radio
 	driversLock     sync.Mutex
 	drivers         []Messages.Drivers
+	lapsLock        sync.Mutex
+	laps            []Messages.Lap
+
+	classificationLock sync.Mutex
+	classification     []Messages.Classification
+	penaltiesLock      sync.Mutex
+	penalties          []Messages.Incident
+
+	locationFramesLock  sync.Mutex
+	locationFrames      []Messages.LocationFrame
+	telemetryFramesLock sync.Mutex
+	telemetryFrames     []Messages.TelemetryFrame
+	carSamplesLock      sync.Mutex
+	carSamples          []Messages.CarSample
+	intervalsLock       sync.Mutex
+	intervals           []Messages.Intervals
+	cornersLock         sync.Mutex
+	corners             []Messages.LapCorners
 
 	currentTime   time.Time
 	currentLap    int
@@ -65,6 +91,10 @@ type realtime struct {
 	sessionStart          time.Time
 	sessionLength         time.Duration
 
+	// When the session time was 0 and the lap the leader was on from the last event
+	sessionTimeStart time.Time
+	leaderLap        int
+
 	ctx context.Context
 	wg  *sync.WaitGroup
 }
@@ -129,6 +159,11 @@ func (f *realtime) Run() {
 								f.sessionLength = f.event[0].RemainingTime
 								f.clockStopped = f.event[0].ClockStopped
 
+								if f.event[0].SessionTime != 0 {
+									f.sessionTimeStart = f.event[0].Timestamp.Add(-f.event[0].SessionTime)
+								}
+								f.leaderLap = f.event[0].LeaderLap
+
 							default:
 								// Data loss
 							}
@@ -156,6 +191,11 @@ func (f *realtime) Run() {
 								f.sessionLength = f.event[0].RemainingTime
 								f.clockStopped = f.event[0].ClockStopped
 
+								if f.event[0].SessionTime != 0 {
+									f.sessionTimeStart = f.event[0].Timestamp.Add(-f.event[0].SessionTime)
+								}
+								f.leaderLap = f.event[0].LeaderLap
+
 							default:
 								// Data loss
 							}
@@ -208,6 +248,20 @@ func (f *realtime) Run() {
 				}
 				f.timingLock.Unlock()
 
+				f.lapsLock.Lock()
+				if len(f.laps) > 0 {
+					for len(f.laps) > 0 && (f.laps[0].Timestamp.Before(f.currentTime) || f.laps[0].Timestamp.Equal(f.currentTime)) {
+						select {
+						case f.outputLaps <- f.laps[0]:
+						default:
+							// Data loss
+						}
+
+						f.laps = f.laps[1:]
+					}
+				}
+				f.lapsLock.Unlock()
+
 				f.telemetryLock.Lock()
 				if len(f.telemetry) > 0 {
 					for len(f.telemetry) > 0 && (f.telemetry[0].Timestamp.Before(f.currentTime) || f.telemetry[0].Timestamp.Equal(f.currentTime)) {
@@ -222,6 +276,20 @@ func (f *realtime) Run() {
 				}
 				f.telemetryLock.Unlock()
 
+				f.telemetryFramesLock.Lock()
+				if len(f.telemetryFrames) > 0 {
+					for len(f.telemetryFrames) > 0 && (f.telemetryFrames[0].Timestamp.Before(f.currentTime) || f.telemetryFrames[0].Timestamp.Equal(f.currentTime)) {
+						select {
+						case f.outputTelemetryFrames <- f.telemetryFrames[0]:
+						default:
+							// Data loss
+						}
+
+						f.telemetryFrames = f.telemetryFrames[1:]
+					}
+				}
+				f.telemetryFramesLock.Unlock()
+
 				f.radioLock.Lock()
 				if len(f.radio) > 0 {
 					for len(f.radio) > 0 && (f.radio[0].Timestamp.Before(f.currentTime) || f.radio[0].Timestamp.Equal(f.currentTime)) {
@@ -245,16 +313,58 @@ func (f *realtime) Run() {
 				f.driversLock.Lock()
 				if len(f.drivers) > 0 {
 					// Send the driver list immediately so that users know who the drivers are before other data comes
-					// through.
+					// through. Each update is the full list so only the latest one matters.
 					select {
-					case f.outputDrivers <- f.drivers[0]:
+					case f.outputDrivers <- f.drivers[len(f.drivers)-1]:
 					default:
 						// Data loss
 					}
 
-					f.drivers = f.drivers[1:]
+					f.drivers = nil
 				}
 				f.driversLock.Unlock()
+
+				f.classificationLock.Lock()
+				if len(f.classification) > 0 {
+					for len(f.classification) > 0 && (f.classification[0].Timestamp.Before(f.currentTime) || f.classification[0].Timestamp.Equal(f.currentTime)) {
+						select {
+						case f.outputClassification <- f.classification[0]:
+						default:
+							// Data loss
+						}
+
+						f.classification = f.classification[1:]
+					}
+				}
+				f.classificationLock.Unlock()
+
+				f.penaltiesLock.Lock()
+				if len(f.penalties) > 0 {
+					for len(f.penalties) > 0 && (f.penalties[0].Timestamp.Before(f.currentTime) || f.penalties[0].Timestamp.Equal(f.currentTime)) {
+						select {
+						case f.outputPenalties <- f.penalties[0]:
+						default:
+							// Data loss
+						}
+
+						f.penalties = f.penalties[1:]
+					}
+				}
+				f.penaltiesLock.Unlock()
+
+				f.cornersLock.Lock()
+				if len(f.corners) > 0 {
+					for len(f.corners) > 0 && (f.corners[0].Timestamp.Before(f.currentTime) || f.corners[0].Timestamp.Equal(f.currentTime)) {
+						select {
+						case f.outputCorners <- f.corners[0]:
+						default:
+							// Data loss
+						}
+
+						f.corners = f.corners[1:]
+					}
+				}
+				f.cornersLock.Unlock()
 			} else {
 				counter++
 			}
@@ -273,6 +383,48 @@ func (f *realtime) Run() {
 			}
 			f.locationLock.Unlock()
 
+			f.locationFramesLock.Lock()
+			if len(f.locationFrames) > 0 {
+				for len(f.locationFrames) > 0 && (f.locationFrames[0].Timestamp.Before(f.currentTime) || f.locationFrames[0].Timestamp.Equal(f.currentTime)) {
+					select {
+					case f.outputLocationFrames <- f.locationFrames[0]:
+					default:
+						// Data loss
+					}
+
+					f.locationFrames = f.locationFrames[1:]
+				}
+			}
+			f.locationFramesLock.Unlock()
+
+			f.carSamplesLock.Lock()
+			if len(f.carSamples) > 0 {
+				for len(f.carSamples) > 0 && (f.carSamples[0].Timestamp.Before(f.currentTime) || f.carSamples[0].Timestamp.Equal(f.currentTime)) {
+					select {
+					case f.outputCarSamples <- f.carSamples[0]:
+					default:
+						// Data loss
+					}
+
+					f.carSamples = f.carSamples[1:]
+				}
+			}
+			f.carSamplesLock.Unlock()
+
+			f.intervalsLock.Lock()
+			if len(f.intervals) > 0 {
+				for len(f.intervals) > 0 && (f.intervals[0].Timestamp.Before(f.currentTime) || f.intervals[0].Timestamp.Equal(f.currentTime)) {
+					select {
+					case f.outputIntervals <- f.intervals[0]:
+					default:
+						// Data loss
+					}
+
+					f.intervals = f.intervals[1:]
+				}
+			}
+			f.intervalsLock.Unlock()
+
 			if !f.currentTime.IsZero() {
 				increment := f.incrementTime
 				if increment > 0 {
@@ -298,7 +450,11 @@ func (f *realtime) Run() {
 					}
 				}
 
-				f.outputEventTime <- Messages.EventTime{Timestamp: f.currentTime, Remaining: f.remainingTime}
+				eventTime := Messages.EventTime{Timestamp: f.currentTime, Remaining: f.remainingTime, LeaderLap: f.leaderLap}
+				if !f.sessionTimeStart.IsZero() {
+					eventTime.SessionTime = f.currentTime.Sub(f.sessionTimeStart)
+				}
+				f.outputEventTime <- eventTime
 
 				f.currentTime = f.currentTime.Add(time.Millisecond * 500)
 			}
@@ -354,6 +510,54 @@ func (f *realtime) AddDrivers(drivers Messages.Drivers) {
 	f.drivers = append(f.drivers, drivers)
 }
 
+func (f *realtime) AddLap(lap Messages.Lap) {
+	f.lapsLock.Lock()
+	defer f.lapsLock.Unlock()
+	f.laps = append(f.laps, lap)
+}
+
+func (f *realtime) AddClassification(classification Messages.Classification) {
+	f.classificationLock.Lock()
+	defer f.classificationLock.Unlock()
+	f.classification = append(f.classification, classification)
+}
+
+func (f *realtime) AddPenalty(penalty Messages.Incident) {
+	f.penaltiesLock.Lock()
+	defer f.penaltiesLock.Unlock()
+	f.penalties = append(f.penalties, penalty)
+}
+
+func (f *realtime) AddLocationFrame(frame Messages.LocationFrame) {
+	f.locationFramesLock.Lock()
+	defer f.locationFramesLock.Unlock()
+	f.locationFrames = append(f.locationFrames, frame)
+}
+
+func (f *realtime) AddTelemetryFrame(frame Messages.TelemetryFrame) {
+	f.telemetryFramesLock.Lock()
+	defer f.telemetryFramesLock.Unlock()
+	f.telemetryFrames = append(f.telemetryFrames, frame)
+}
+
+func (f *realtime) AddCarSample(sample Messages.CarSample) {
+	f.carSamplesLock.Lock()
+	defer f.carSamplesLock.Unlock()
+	f.carSamples = append(f.carSamples, sample)
+}
+
+func (f *realtime) AddIntervals(intervals Messages.Intervals) {
+	f.intervalsLock.Lock()
+	defer f.intervalsLock.Unlock()
+	f.intervals = append(f.intervals, intervals)
+}
+
+func (f *realtime) AddLapCorners(corners Messages.LapCorners) {
+	f.cornersLock.Lock()
+	defer f.cornersLock.Unlock()
+	f.corners = append(f.corners, corners)
+}
+
 func (f *realtime) IncrementLap() {
 	f.incrementLapCount++
 }
@@ -30,6 +30,14 @@ type straightThrough struct {
 	outputEventTime           chan<- Messages.EventTime
 	outputRadio               chan<- Messages.Radio
 	outputDrivers             chan<- Messages.Drivers
+	outputLaps                chan<- Messages.Lap
+	outputClassification      chan<- Messages.Classification
+	outputPenalties           chan<- Messages.Incident
+	outputLocationFrames      chan<- Messages.LocationFrame
+	outputTelemetryFrames     chan<- Messages.TelemetryFrame
+	outputCarSamples          chan<- Messages.CarSample
+	outputIntervals           chan<- Messages.Intervals
+	outputCorners             chan<- Messages.LapCorners
 
 	isPaused bool
 }
@@ -53,7 +61,7 @@ func (f *straightThrough) AddTiming(timing Messages.Timing) {
 func (f *straightThrough) AddEvent(event Messages.Event) {
 	f.outputEvent <- event
 
-	f.outputEventTime <- Messages.EventTime{Timestamp: event.Timestamp}
+	f.outputEventTime <- Messages.EventTime{Timestamp: event.Timestamp, SessionTime: event.SessionTime, LeaderLap: event.LeaderLap}
 }
 
 func (f *straightThrough) AddTelemetry(telemetry Messages.Telemetry) {
@@ -72,6 +80,38 @@ func (f *straightThrough) AddDrivers(drivers Messages.Drivers) {
 	f.outputDrivers <- drivers
 }
 
+func (f *straightThrough) AddLap(lap Messages.Lap) {
+	f.outputLaps <- lap
+}
+
+func (f *straightThrough) AddClassification(classification Messages.Classification) {
+	f.outputClassification <- classification
+}
+
+func (f *straightThrough) AddPenalty(penalty Messages.Incident) {
+	f.outputPenalties <- penalty
+}
+
+func (f *straightThrough) AddLocationFrame(frame Messages.LocationFrame) {
+	f.outputLocationFrames <- frame
+}
+
+func (f *straightThrough) AddTelemetryFrame(frame Messages.TelemetryFrame) {
+	f.outputTelemetryFrames <- frame
+}
+
+func (f *straightThrough) AddCarSample(sample Messages.CarSample) {
+	f.outputCarSamples <- sample
+}
+
+func (f *straightThrough) AddIntervals(intervals Messages.Intervals) {
+	f.outputIntervals <- intervals
+}
+
+func (f *straightThrough) AddLapCorners(corners Messages.LapCorners) {
+	f.outputCorners <- corners
+}
+
 func (f *straightThrough) IncrementLap() {}
 
 func (f *straightThrough) IncrementTime(duration time.Duration) {}
@@ -22,11 +22,67 @@ import (
 	"time"
 )
 
+// Channel ids used by the cars up until now, new regulations can add or change these
+func DefaultTelemetryChannels() map[int]Messages.TelemetryChannel {
+	return map[int]Messages.TelemetryChannel{
+		0:  Messages.RPMChannel,
+		2:  Messages.SpeedChannel,
+		3:  Messages.GearChannel,
+		4:  Messages.ThrottleChannel,
+		5:  Messages.BrakeChannel,
+		45: Messages.DRSChannel,
+	}
+}
+
+// Values for the DRS channel. 8 is eligible for DRS in the next zone, 10 is armed in a zone and 12 and 14 are open.
+func DefaultDRSStates() map[int]Messages.CarDRSState {
+	return map[int]Messages.CarDRSState{
+		0:  Messages.CarDRSOff,
+		1:  Messages.CarDRSOff,
+		8:  Messages.CarDRSAvailable,
+		10: Messages.CarDRSArmed,
+		12: Messages.CarDRSOpen,
+		14: Messages.CarDRSOpen,
+	}
+}
+
+func (p *Parser) SetTelemetryChannels(channels map[int]Messages.TelemetryChannel) {
+	tmp := make(map[int]Messages.TelemetryChannel, len(channels))
+	for id, channel := range channels {
+		tmp[id] = channel
+	}
+
+	p.telemetryChannelsLock.Lock()
+	defer p.telemetryChannelsLock.Unlock()
+	p.telemetryChannels = tmp
+}
+
+func (p *Parser) SetDRSStates(states map[int]Messages.CarDRSState) {
+	tmp := make(map[int]Messages.CarDRSState, len(states))
+	for value, state := range states {
+		tmp[value] = state
+	}
+
+	p.telemetryChannelsLock.Lock()
+	defer p.telemetryChannelsLock.Unlock()
+	p.drsStates = tmp
+}
+
 func (p *Parser) parseCarData(dat map[string]interface{}, timestamp time.Time) ([]Messages.Telemetry, []Messages.Timing, error) {
 
 	result := make([]Messages.Telemetry, 0)
 	timingResult := make([]Messages.Timing, 0)
 
+	// The maps are replaced rather than changed when set so we only need to hold the lock to get them
+	p.telemetryChannelsLock.Lock()
+	channels := p.telemetryChannels
+	drsStates := p.drsStates
+	p.telemetryChannelsLock.Unlock()
+
+	policy := p.currentTelemetryPolicy()
+	lapDistance := p.currentLapDistance()
+	bufferLapTelemetry := p.bufferLapTelemetry()
+
 	entries := dat["Entries"].([]interface{})
 	for _, record := range entries {
 
@@ -35,62 +91,82 @@ func (p *Parser) parseCarData(dat map[string]interface{}, timestamp time.Time) (
 		if err != nil {
 			p.ParseTimeError(connection.CarDataFile, timestamp, "Utc", err)
 		}
-		localTimestamp := utcTimestamp.In(p.timezone)
 
 		for driverId, car := range record.(map[string]interface{})["Cars"].(map[string]interface{}) {
 			driverNum, _ := strconv.Atoi(driverId)
 
+			carChannels := car.(map[string]interface{})["Channels"].(map[string]interface{})
+
 			t := Messages.Telemetry{
-				Timestamp:    localTimestamp,
+				Timestamp:    utcTimestamp,
 				DriverNumber: driverNum,
 			}
 
-			for id, channel := range car.(map[string]interface{})["Channels"].(map[string]interface{}) {
-				switch id {
-				case "0": // RPM
-					t.RPM = int16(channel.(float64))
-				case "2": // Speed
-					t.Speed = float32(channel.(float64))
-				case "3": // Gear
-					t.Gear = byte(channel.(float64))
-				case "4": // Throttle
-					t.Throttle = float32(channel.(float64))
-				case "5": // Brake
-					t.Brake = float32(channel.(float64))
-				case "45": // DRS
+			for id, channel := range carChannels {
+				channelId, err := strconv.Atoi(id)
+				if err != nil {
+					p.ParseErrorf(connection.CarDataFile, timestamp, "Invalid channel id '%s'", id)
+					continue
+				}
+
+				value, _ := channel.(float64)
+
+				switch channels[channelId] {
+				case Messages.RPMChannel:
+					t.RPM = int16(value)
+				case Messages.SpeedChannel:
+					t.Speed = float32(value)
+				case Messages.GearChannel:
+					t.Gear = byte(value)
+				case Messages.ThrottleChannel:
+					t.Throttle = float32(value)
+				case Messages.BrakeChannel:
+					t.Brake = float32(value)
+				case Messages.DRSChannel:
 					driverInfo, _ := p.driverTimes[driverId]
 
-					drsOpen := false
-					drsValue := int(channel.(float64))
-					if drsValue == 10 || drsValue == 12 || drsValue == 14 {
-						t.DRS = true
-						drsOpen = true
-					} else {
-						t.DRS = false
-						drsOpen = false
-					}
+					t.DRSRaw = int(value)
+					t.DRSState = drsStates[t.DRSRaw]
+					// Armed has always counted as open for the DRS flags so keep it that way, the state tells them apart
+					t.DRS = t.DRSState == Messages.CarDRSOpen || t.DRSState == Messages.CarDRSArmed
 
-					if drsOpen != driverInfo.DRSOpen {
-						driverInfo.DRSOpen = drsOpen
+					if t.DRS != driverInfo.DRSOpen {
+						driverInfo.DRSOpen = t.DRS
 						p.driverTimes[driverId] = driverInfo
 						timingResult = append(timingResult, driverInfo)
 					}
-
 				default:
-					p.ParseErrorf(connection.CarDataFile, timestamp, "Unhandled channel id '%s'", id)
+					// Channels we don't know about are still available in the channels map. Only
+					// create the map when one turns up so the known channels don't cost an allocation.
+					if t.Channels == nil {
+						t.Channels = make(map[int]float64)
+					}
+					t.Channels[channelId] = value
 				}
 			}
 
-			// Don't send telemetry data is the car is turned off to improve performance
-			if t.RPM == 0 {
-				continue
+			knownLapDistance := p.telemetryLapDistance(lapDistance, &t)
+
+			// Resampling needs every sample for every car whatever the selection and policy are
+			if p.requestedData&CarSamples == CarSamples {
+				p.resampleAddTelemetry(t)
+			}
+
+			// So does splitting the telemetry into laps, which also needs to know where the car was on the lap
+			if bufferLapTelemetry && knownLapDistance {
+				p.lapTelemetrySample(t)
 			}
 
 			// Only send the telemetry info if has been requested for this driver
 			p.sendTelemetryLock.Lock()
 			_, sendTelemetry := p.sendTelemetryFor[driverNum]
 			p.sendTelemetryLock.Unlock()
-			if sendTelemetry {
+			if !sendTelemetry {
+				continue
+			}
+
+			// By default don't send telemetry data if the car is turned off to improve performance
+			if p.applyTelemetryPolicy(policy, t) {
 				result = append(result, t)
 			}
 		}
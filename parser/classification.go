@@ -0,0 +1,179 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+func (p *Parser) FinalClassification() Messages.Classification {
+	p.classificationLock.Lock()
+	defer p.classificationLock.Unlock()
+
+	result := p.classification
+	result.Entries = make([]Messages.ClassificationEntry, len(p.classification.Entries))
+	copy(result.Entries, p.classification.Entries)
+	return result
+}
+
+func (p *Parser) createClassification(timestamp time.Time) Messages.Classification {
+	isRace := p.eventState.Type == Messages.Race || p.eventState.Type == Messages.Sprint
+
+	leaderLaps := 0
+	for _, driver := range p.driverTimes {
+		leaderLaps = max(leaderLaps, driver.Lap)
+	}
+
+	entries := make([]Messages.ClassificationEntry, 0, len(p.driverTimes))
+	for driverNumber, driver := range p.driverTimes {
+		entry := Messages.ClassificationEntry{
+			Position:            driver.Position,
+			Number:              driver.Number,
+			Name:                driver.Name,
+			ShortName:           driver.ShortName,
+			Team:                driver.Team,
+			HexColor:            driver.HexColor,
+			Laps:                driver.Lap,
+			Gap:                 driver.GapToLeader,
+			Status:              Messages.ClassifiedFinished,
+			FastestLap:          driver.FastestLap,
+			OverallFastestLap:   driver.OverallFastestLap,
+			TimePenaltySeconds:  driver.TimePenaltySeconds,
+			TrackLimitsWarnings: driver.TrackLimitsWarnings,
+			BlueFlags:           driver.BlueFlags,
+			BlackAndWhiteFlags:  driver.BlackAndWhiteFlags,
+			Penalties:           p.PenaltySummary(driver.Number),
+		}
+
+		p.lapHistoryLock.Lock()
+		laps := p.lapHistory[driverNumber]
+		if len(laps) == driver.Lap {
+			for _, lap := range laps {
+				if lap.LapTime == 0 {
+					entry.TotalTime = 0
+					break
+				}
+				entry.TotalTime += lap.LapTime
+			}
+		}
+		p.lapHistoryLock.Unlock()
+
+		started := driver.Lap > 0
+		for x := 0; x < len(driver.Segment) && !started; x++ {
+			started = driver.Segment[x] != Messages.None
+		}
+
+		retired := driver.Retired || driver.Location == Messages.OutOfRace ||
+			(isRace && (driver.Stopped || driver.Location == Messages.Stopped) && !driver.ChequeredFlag)
+
+		if p.disqualified[driverNumber] {
+			entry.Status = Messages.ClassifiedDSQ
+		} else if !started {
+			entry.Status = Messages.ClassifiedDNS
+		} else if retired {
+			entry.Status = Messages.ClassifiedDNF
+		} else if isRace && driver.Lap < leaderLaps {
+			entry.Status = Messages.ClassifiedLapped
+			entry.LapsDown = leaderLaps - driver.Lap
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// Finishers in position order then retirements by the most laps done and then non starters and
+	// disqualifications
+	sort.SliceStable(entries, func(i, j int) bool {
+		groupI := classificationGroup(entries[i].Status)
+		groupJ := classificationGroup(entries[j].Status)
+		if groupI != groupJ {
+			return groupI < groupJ
+		}
+
+		if entries[i].Status == Messages.ClassifiedDNF && entries[i].Laps != entries[j].Laps {
+			return entries[i].Laps > entries[j].Laps
+		}
+
+		if entries[i].Position != entries[j].Position {
+			// No position goes to the back
+			if entries[i].Position == 0 || entries[j].Position == 0 {
+				return entries[j].Position == 0
+			}
+			return entries[i].Position < entries[j].Position
+		}
+
+		return entries[i].Number < entries[j].Number
+	})
+
+	for x := range entries {
+		entries[x].Position = x + 1
+	}
+
+	// Only the winner gets a total time, everyone else is a gap
+	if len(entries) > 0 && isRace {
+		winnerTime := entries[0].TotalTime
+		entries[0].Gap = Messages.Gap{}
+		for x := 1; x < len(entries); x++ {
+			if winnerTime > 0 && entries[x].Gap.Type == Messages.TimeGap {
+				entries[x].TotalTime = winnerTime + entries[x].Gap.Time
+			} else {
+				entries[x].TotalTime = 0
+			}
+		}
+	}
+
+	classification := Messages.Classification{
+		Timestamp: timestamp,
+		Status:    p.eventState.Status,
+		Entries:   entries,
+	}
+
+	p.classificationLock.Lock()
+	p.classification = classification
+	p.classificationLock.Unlock()
+
+	return classification
+}
+
+func classificationGroup(status Messages.ClassificationStatus) int {
+	switch status {
+	case Messages.ClassifiedFinished, Messages.ClassifiedLapped:
+		return 0
+	case Messages.ClassifiedDNF:
+		return 1
+	case Messages.ClassifiedDNS:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Forget the result of the previous session
+func (p *Parser) resetClassification() {
+	p.disqualified = make(map[string]bool)
+
+	p.classificationLock.Lock()
+	p.classification = Messages.Classification{}
+	p.classificationLock.Unlock()
+}
+
+func (p *Parser) driverDisqualified(driverNumber string) {
+	if _, exists := p.driverTimes[driverNumber]; exists {
+		p.disqualified[driverNumber] = true
+	}
+}
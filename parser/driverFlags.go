@@ -0,0 +1,80 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// Race control doesn't say when a blue flag is no longer being shown so treat it as active for this long
+const blueFlagDuration = 30 * time.Second
+
+// Record blue and black and white flags shown to a driver
+func (p *Parser) readDriverFlag(rcm Messages.RaceControlMessage, timingResult *[]Messages.Timing) {
+	if rcm.Flag != Messages.BlueFlag && rcm.Flag != Messages.BlackAndWhite {
+		return
+	}
+
+	// Older data doesn't have the racing number so get it from the message
+	driverNumber := rcm.RacingNumber
+	if driverNumber == 0 {
+		cars := p.incidentCars(rcm.Msg)
+		if len(cars) == 0 {
+			return
+		}
+		driverNumber = cars[0]
+	}
+
+	currentDriver, exists := p.driverTimes[strconv.Itoa(driverNumber)]
+	if !exists {
+		return
+	}
+
+	if rcm.Flag == Messages.BlueFlag {
+		currentDriver.BlueFlag = true
+		currentDriver.BlueFlagTime = rcm.Timestamp
+		currentDriver.BlueFlags++
+	} else {
+		currentDriver.BlackAndWhiteFlag = true
+		currentDriver.BlackAndWhiteFlagTime = rcm.Timestamp
+		currentDriver.BlackAndWhiteFlags++
+	}
+
+	currentDriver.Timestamp = rcm.Timestamp
+	p.driverTimes[strconv.Itoa(driverNumber)] = currentDriver
+	*timingResult = append(*timingResult, currentDriver)
+}
+
+// Remove any blue flags that have been shown for long enough, returns the drivers that changed
+func (p *Parser) expireBlueFlags(timestamp time.Time) []Messages.Timing {
+	var result []Messages.Timing
+
+	for driverNumber, driver := range p.driverTimes {
+		if !driver.BlueFlag || timestamp.Sub(driver.BlueFlagTime) < blueFlagDuration {
+			continue
+		}
+
+		driver.BlueFlag = false
+		driver.Timestamp = timestamp
+		p.driverTimes[driverNumber] = driver
+		result = append(result, driver)
+	}
+
+	return result
+}
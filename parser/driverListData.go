@@ -20,78 +20,181 @@ import (
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"github.com/f1gopher/f1gopherlib/connection"
 	"image/color"
+	"sort"
 	"strconv"
 	"time"
 )
 
 func (p *Parser) parseDriverList(dat map[string]interface{}, timestamp time.Time) []Messages.Drivers {
-	var driver []Messages.Drivers = nil
+	changed := false
 
 	for driverNum, info := range dat {
 		if driverNum == "_kf" {
 			continue
 		}
 
-		record := info.(map[string]interface{})
+		record, ok := info.(map[string]interface{})
+		if !ok {
+			continue
+		}
 
 		current, exists := p.driverTimes[driverNum]
+		driverInfo, infoExists := p.driverInfo[driverNum]
+		previous := driverInfo
 
-		if !exists {
+		if !exists || !infoExists {
 			number, _ := strconv.Atoi(driverNum)
 
-			line := 0
-			rawLine, exists := record["Line"]
-			if exists {
-				line = int(rawLine.(float64))
+			// Default colors
+			current.Number = number
+			current.Color = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			driverInfo = Messages.DriverInfo{
+				Number: number,
+				Color:  current.Color,
 			}
+		}
 
-			fullName, _ := record["FullName"].(string)
-			shortName, _ := record["Tla"].(string)
-			// TeamName and TeamColor do not always exist
-			teamName, _ := record["TeamName"].(string)
-			teamHexColour, colorExists := record["TeamColour"].(string)
+		// Updates only contain the values that have changed so only overwrite what we are given
+		rawLine, hasLine := record["Line"].(float64)
+		if hasLine {
+			driverInfo.Line = int(rawLine)
+		}
 
-			// Default colors
-			teamColor := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
-			if colorExists {
-				_, err := fmt.Sscanf(teamHexColour, "%02x%02x%02x", &teamColor.R, &teamColor.G, &teamColor.B)
-				if err != nil {
-					p.ParseErrorf(connection.DriverListFile, timestamp, "Unable to parse team color: '%s', %v", teamColor, err)
-				}
+		readString := func(name string, value *string) {
+			if newValue, exists := record[name].(string); exists {
+				*value = newValue
 			}
+		}
 
-			current = Messages.Timing{
-				Number:    number,
-				Position:  line,
-				Name:      fullName,
-				ShortName: shortName,
-				Team:      teamName,
-				HexColor:  "#" + teamHexColour,
-				Color:     teamColor,
+		readString("FullName", &driverInfo.Name)
+		readString("Tla", &driverInfo.ShortName)
+		// TeamName and TeamColor do not always exist
+		readString("TeamName", &driverInfo.Team)
+		readString("FirstName", &driverInfo.FirstName)
+		readString("LastName", &driverInfo.LastName)
+		readString("BroadcastName", &driverInfo.BroadcastName)
+		readString("CountryCode", &driverInfo.CountryCode)
+		readString("Reference", &driverInfo.Reference)
+		readString("HeadshotUrl", &driverInfo.HeadshotUrl)
+
+		teamHexColour, colorExists := record["TeamColour"].(string)
+		if colorExists {
+			teamColor := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			_, err := fmt.Sscanf(teamHexColour, "%02x%02x%02x", &teamColor.R, &teamColor.G, &teamColor.B)
+			if err != nil {
+				p.ParseErrorf(connection.DriverListFile, timestamp, "Unable to parse team color: '%s', %v", teamHexColour, err)
 			}
+			driverInfo.HexColor = "#" + teamHexColour
+			driverInfo.Color = teamColor
+		}
+
+		// The first line we see for a driver is where they start from
+		if !exists || !infoExists {
+			driverInfo.StartPosition = driverInfo.Line
+			current.Position = driverInfo.Line
+		}
 
-			if driver == nil {
-				driver = []Messages.Drivers{
-					{
-						Timestamp: timestamp,
-						Drivers:   nil,
-					},
-				}
+		if driverInfo.HeadshotUrl != previous.HeadshotUrl {
+			driverInfo.Headshot = nil
+
+			if p.requestedData&DriverHeadshots == DriverHeadshots && len(driverInfo.HeadshotUrl) > 0 {
+				p.fetchHeadshot(driverInfo.Number, driverInfo.HeadshotUrl, timestamp)
 			}
+		}
 
-			driver[0].Drivers = append(driver[0].Drivers, Messages.DriverInfo{
-				StartPosition: current.Position,
-				Name:          current.Name,
-				ShortName:     current.ShortName,
-				Number:        current.Number,
-				Team:          current.Team,
-				HexColor:      current.HexColor,
-				Color:         current.Color,
-			})
+		current.Name = driverInfo.Name
+		current.ShortName = driverInfo.ShortName
+		current.Team = driverInfo.Team
+		current.HexColor = driverInfo.HexColor
+		current.Color = driverInfo.Color
+
+		if !infoExists || driverInfoChanged(previous, driverInfo) {
+			changed = true
 		}
 
+		p.driverInfo[driverNum] = driverInfo
 		p.driverTimes[driverNum] = current
 	}
 
-	return driver
+	if !changed {
+		return nil
+	}
+
+	return []Messages.Drivers{p.driverList(timestamp)}
+}
+
+// Always send the full list so consumers can replace what they have
+func (p *Parser) driverList(timestamp time.Time) Messages.Drivers {
+	result := Messages.Drivers{
+		Timestamp: timestamp,
+		Drivers:   make([]Messages.DriverInfo, 0, len(p.driverInfo)),
+	}
+	for _, driverInfo := range p.driverInfo {
+		result.Drivers = append(result.Drivers, driverInfo)
+	}
+	sort.Slice(result.Drivers, func(i, j int) bool {
+		if result.Drivers[i].StartPosition != result.Drivers[j].StartPosition {
+			return result.Drivers[i].StartPosition < result.Drivers[j].StartPosition
+		}
+		return result.Drivers[i].Number < result.Drivers[j].Number
+	})
+
+	return result
+}
+
+type headshotResult struct {
+	driverNumber int
+	url          string
+	headshot     []byte
+	timestamp    time.Time
+}
+
+// Fetching a headshot can be slow so do it in the background and hand the result back to the parser. The fetch is
+// tracked by the wait group so that it has finished with the cache before the session is stopped.
+func (p *Parser) fetchHeadshot(driverNumber int, url string, timestamp time.Time) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		headshot, err := p.assets.DriverHeadshot(url)
+		if err != nil {
+			p.ParseErrorf(connection.DriverListFile, timestamp, "Unable to get headshot for driver %d: %v", driverNumber, err)
+			return
+		}
+
+		select {
+		case p.headshots <- headshotResult{driverNumber: driverNumber, url: url, headshot: headshot, timestamp: timestamp}:
+		case <-p.ctx.Done():
+		}
+	}()
+}
+
+func (p *Parser) updateHeadshot(result headshotResult) []Messages.Drivers {
+	driverNum := strconv.Itoa(result.driverNumber)
+
+	// Ignore it if the url has changed since we started fetching
+	driverInfo, exists := p.driverInfo[driverNum]
+	if !exists || driverInfo.HeadshotUrl != result.url {
+		return nil
+	}
+
+	driverInfo.Headshot = result.headshot
+	p.driverInfo[driverNum] = driverInfo
+
+	return []Messages.Drivers{p.driverList(result.timestamp)}
+}
+
+// Line changes with every position change during a race so only resend the list when the driver details change
+func driverInfoChanged(a Messages.DriverInfo, b Messages.DriverInfo) bool {
+	return a.Name != b.Name ||
+		a.ShortName != b.ShortName ||
+		a.Team != b.Team ||
+		a.HexColor != b.HexColor ||
+		a.FirstName != b.FirstName ||
+		a.LastName != b.LastName ||
+		a.BroadcastName != b.BroadcastName ||
+		a.CountryCode != b.CountryCode ||
+		a.Reference != b.Reference ||
+		a.HeadshotUrl != b.HeadshotUrl ||
+		len(a.Headshot) != len(b.Headshot)
 }
@@ -0,0 +1,57 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// Samples for the same record are next to each other so group them into one frame per timestamp
+func locationFrames(locations []Messages.Location) []Messages.LocationFrame {
+	result := make([]Messages.LocationFrame, 0)
+
+	for _, location := range locations {
+		if len(result) == 0 || !result[len(result)-1].Timestamp.Equal(location.Timestamp) {
+			result = append(result, Messages.LocationFrame{
+				Timestamp: location.Timestamp,
+				Locations: make([]Messages.Location, 0, 20),
+			})
+		}
+
+		frame := &result[len(result)-1]
+		frame.Locations = append(frame.Locations, location)
+	}
+
+	return result
+}
+
+func telemetryFrames(telemetry []Messages.Telemetry) []Messages.TelemetryFrame {
+	result := make([]Messages.TelemetryFrame, 0)
+
+	for _, sample := range telemetry {
+		if len(result) == 0 || !result[len(result)-1].Timestamp.Equal(sample.Timestamp) {
+			result = append(result, Messages.TelemetryFrame{
+				Timestamp: sample.Timestamp,
+				Telemetry: make([]Messages.Telemetry, 0, 20),
+			})
+		}
+
+		frame := &result[len(result)-1]
+		frame.Telemetry = append(frame.Telemetry, sample)
+	}
+
+	return result
+}
@@ -29,6 +29,7 @@ func (p *Parser) parseHeartbeatData(dat map[string]interface{}, timestamp time.T
 		p.ParseTimeError(connection.HeartbeatFile, timestamp, "Utc", err)
 	} else {
 		p.eventState.Timestamp = value
+		p.expireSectorFlags(value)
 	}
 
 	// TODO - ignore _kf or flag when no heartbeat recieved?
@@ -0,0 +1,206 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// How far back to keep where cars were, gaps bigger than this aren't worked out
+const intervalHistory = 5 * time.Minute
+
+// Cars without a location for this long are left out
+const intervalTimeout = 5 * time.Second
+
+// The timing changes lap at a different time to the location crossing the line so only use its lap count
+// when the car is at least this many metres away from the line
+const intervalLineMargin = 500.0
+
+type distanceAt struct {
+	timestamp time.Time
+	distance  float64
+}
+
+// Distance covered by a car over time
+type intervalTrace struct {
+	laps         int
+	lastDistance float64
+	history      []distanceAt
+
+	// No location for the car at the moment
+	noFix bool
+	// The lap count was a guess, check it against the timing once the car is away from the line
+	checkLaps bool
+}
+
+// Estimate the gaps between cars every time we get new locations
+func (p *Parser) intervals(locations []Messages.Location) []Messages.Intervals {
+	result := make([]Messages.Intervals, 0)
+
+	lapDistance := p.currentLapDistance()
+	if lapDistance == nil {
+		return result
+	}
+	length := lapDistance.Length()
+
+	for _, frame := range locationFrames(locations) {
+		for _, location := range frame.Locations {
+			trace, exists := p.intervalTraces[location.DriverNumber]
+
+			// Keep the trace so the laps carry on from where they were when the location comes back
+			if location.Status == Messages.PositionNoFix {
+				if exists {
+					trace.noFix = true
+					trace.checkLaps = true
+				}
+				continue
+			}
+
+			timingLaps := p.driverTimes[strconv.Itoa(location.DriverNumber)].Lap
+			if !exists {
+				trace = &intervalTrace{laps: timingLaps, checkLaps: true}
+				p.intervalTraces[location.DriverNumber] = trace
+			} else {
+				// Count the laps ourselves because the timing changes lap at a different time to the location
+				change := location.LapDistance - trace.lastDistance
+				if change < -length/2 {
+					trace.laps++
+				} else if change > length/2 {
+					trace.laps--
+				}
+			}
+			trace.lastDistance = location.LapDistance
+			trace.noFix = false
+
+			if trace.checkLaps && location.LapDistance > intervalLineMargin && location.LapDistance < length-intervalLineMargin {
+				trace.correctLaps(timingLaps, length)
+			}
+
+			trace.history = append(trace.history, distanceAt{
+				timestamp: location.Timestamp,
+				distance:  float64(trace.laps)*length + location.LapDistance,
+			})
+
+			x := 0
+			for x < len(trace.history) && location.Timestamp.Sub(trace.history[x].timestamp) > intervalHistory {
+				x++
+			}
+			trace.history = trace.history[x:]
+		}
+
+		result = append(result, p.createIntervals(frame.Timestamp, length))
+	}
+
+	return result
+}
+
+func (p *Parser) createIntervals(timestamp time.Time, length float64) Messages.Intervals {
+	result := Messages.Intervals{
+		Timestamp: timestamp,
+		Cars:      make([]Messages.CarInterval, 0, len(p.intervalTraces)),
+	}
+
+	traces := make(map[int]*intervalTrace)
+	for driverNumber, trace := range p.intervalTraces {
+		if trace.noFix || len(trace.history) == 0 {
+			continue
+		}
+
+		last := trace.history[len(trace.history)-1]
+		if timestamp.Sub(last.timestamp) > intervalTimeout {
+			continue
+		}
+
+		traces[driverNumber] = trace
+		result.Cars = append(result.Cars, Messages.CarInterval{
+			DriverNumber: driverNumber,
+			Laps:         trace.laps,
+			Distance:     last.distance,
+			InPit:        p.inPitLane(driverNumber),
+		})
+	}
+
+	sort.Slice(result.Cars, func(i, j int) bool {
+		return result.Cars[i].Distance > result.Cars[j].Distance
+	})
+
+	for x := range result.Cars {
+		car := &result.Cars[x]
+		car.Position = x + 1
+
+		if x == 0 {
+			continue
+		}
+
+		now := traces[car.DriverNumber].history[len(traces[car.DriverNumber].history)-1].timestamp
+		ahead := result.Cars[x-1]
+		leader := result.Cars[0]
+
+		car.LapsDown = int((leader.Distance - car.Distance) / length)
+
+		if passed, found := traces[ahead.DriverNumber].timeAt(car.Distance); found {
+			car.GapToCarAhead = now.Sub(passed)
+		}
+		if passed, found := traces[leader.DriverNumber].timeAt(car.Distance); found {
+			car.GapToLeader = now.Sub(passed)
+		}
+	}
+
+	return result
+}
+
+// Use the lap count from the timing and move the distances we already have onto the same lap
+func (t *intervalTrace) correctLaps(laps int, length float64) {
+	t.checkLaps = false
+
+	change := laps - t.laps
+	if change == 0 {
+		return
+	}
+
+	t.laps = laps
+	for x := range t.history {
+		t.history[x].distance += float64(change) * length
+	}
+}
+
+// When the car had covered the distance
+func (t *intervalTrace) timeAt(distance float64) (time.Time, bool) {
+	for x := 1; x < len(t.history); x++ {
+		after := t.history[x]
+		if after.distance < distance {
+			continue
+		}
+
+		before := t.history[x-1]
+		if before.distance > distance {
+			return time.Time{}, false
+		}
+
+		fraction := 0.0
+		if after.distance > before.distance {
+			fraction = (distance - before.distance) / (after.distance - before.distance)
+		}
+
+		return before.timestamp.Add(time.Duration(fraction * float64(after.timestamp.Sub(before.timestamp)))), true
+	}
+
+	return time.Time{}, false
+}
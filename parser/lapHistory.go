@@ -0,0 +1,187 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// Info for the lap a driver is currently on that isn't kept once the timing moves on to the next lap
+type lapTracker struct {
+	lap Messages.Lap
+
+	// Segments get cleared as soon as the car starts the next lap which can be before we are told the lap count
+	// has changed so keep a copy of the segments from the lap that just finished
+	completedSegments    [Messages.MaxSegments]Messages.SegmentType
+	hasCompletedSegments bool
+}
+
+func (p *Parser) LapHistory(driverNumber int) []Messages.Lap {
+	p.lapHistoryLock.Lock()
+	defer p.lapHistoryLock.Unlock()
+
+	laps := p.lapHistory[strconv.Itoa(driverNumber)]
+	result := make([]Messages.Lap, len(laps))
+	copy(result, laps)
+	return result
+}
+
+func (p *Parser) currentLap(driverNumber int) *lapTracker {
+	driver := strconv.Itoa(driverNumber)
+
+	tracker, exists := p.lapTrackers[driver]
+	if !exists {
+		tracker = &lapTracker{}
+		tracker.lap.TrackStatus = p.eventState.TrackStatus
+		tracker.lap.SafetyCar = p.eventState.SafetyCar
+		p.lapTrackers[driver] = tracker
+	}
+
+	return tracker
+}
+
+func (p *Parser) lapSegmentsCompleted(driver *Messages.Timing) {
+	tracker := p.currentLap(driver.Number)
+	tracker.completedSegments = driver.Segment
+	tracker.hasCompletedSegments = true
+}
+
+func (p *Parser) lapSectorTime(driver *Messages.Timing, sector string, sectorTime time.Duration) {
+	if sectorTime == 0 {
+		return
+	}
+
+	tracker := p.currentLap(driver.Number)
+	switch sector {
+	case "0":
+		tracker.lap.Sector1 = sectorTime
+	case "1":
+		tracker.lap.Sector2 = sectorTime
+	case "2":
+		tracker.lap.Sector3 = sectorTime
+	}
+}
+
+// Returns the updated lap to send again when the lap time is for a lap that has already been completed
+func (p *Parser) lapTime(driver *Messages.Timing, lapTime time.Duration, timestamp time.Time) (Messages.Lap, bool) {
+	tracker := p.currentLap(driver.Number)
+
+	// Lap times can arrive after we have been told the lap has finished so update the last lap if it is missing one
+	if tracker.lap.LapTime == 0 && tracker.lap.Sector1 == 0 {
+		p.lapHistoryLock.Lock()
+		laps := p.lapHistory[strconv.Itoa(driver.Number)]
+		if len(laps) > 0 && laps[len(laps)-1].Lap == driver.Lap && laps[len(laps)-1].LapTime == 0 {
+			laps[len(laps)-1].LapTime = lapTime
+			laps[len(laps)-1].Timestamp = timestamp
+			updated := laps[len(laps)-1]
+			p.lapHistoryLock.Unlock()
+			return updated, true
+		}
+		p.lapHistoryLock.Unlock()
+	}
+
+	tracker.lap.LapTime = lapTime
+	return Messages.Lap{}, false
+}
+
+// Forget the laps from the previous session
+func (p *Parser) resetLapHistory() {
+	p.lapTrackers = make(map[string]*lapTracker)
+
+	p.lapHistoryLock.Lock()
+	p.lapHistory = make(map[string][]Messages.Lap)
+	p.lapHistoryLock.Unlock()
+}
+
+func (p *Parser) lapPitIn(driver *Messages.Timing) {
+	p.currentLap(driver.Number).lap.PitIn = true
+}
+
+func (p *Parser) lapPitOut(driver *Messages.Timing) {
+	p.currentLap(driver.Number).lap.PitOut = true
+}
+
+// Track the worst conditions seen during the lap for all drivers
+func (p *Parser) updateLapTrackStatus() {
+	for _, driver := range p.driverTimes {
+		tracker := p.currentLap(driver.Number)
+		tracker.lap.TrackStatus = worstFlag(tracker.lap.TrackStatus, p.eventState.TrackStatus)
+		tracker.lap.SafetyCar = worstSafetyCar(tracker.lap.SafetyCar, p.eventState.SafetyCar)
+	}
+}
+
+func (p *Parser) completeLap(driver *Messages.Timing, timestamp time.Time) Messages.Lap {
+	tracker := p.currentLap(driver.Number)
+
+	lap := tracker.lap
+	lap.Timestamp = timestamp
+	lap.DriverNumber = driver.Number
+	lap.Lap = driver.Lap
+	lap.Tire = driver.Tire
+	lap.LapsOnTire = driver.LapsOnTire
+	lap.Position = driver.Position
+	lap.TrackStatus = worstFlag(lap.TrackStatus, p.eventState.TrackStatus)
+	lap.SafetyCar = worstSafetyCar(lap.SafetyCar, p.eventState.SafetyCar)
+	if tracker.hasCompletedSegments && driver.PreviousSegmentIndex < p.eventState.Sector1Segments {
+		lap.Segment = tracker.completedSegments
+	} else {
+		lap.Segment = driver.Segment
+	}
+
+	p.lapHistoryLock.Lock()
+	p.lapHistory[strconv.Itoa(driver.Number)] = append(p.lapHistory[strconv.Itoa(driver.Number)], lap)
+	p.lapHistoryLock.Unlock()
+
+	// Start the next lap with the current conditions and if we are leaving the pits then it is an out lap
+	*tracker = lapTracker{}
+	tracker.lap.TrackStatus = p.eventState.TrackStatus
+	tracker.lap.SafetyCar = p.eventState.SafetyCar
+	tracker.lap.PitOut = driver.Location == Messages.Pitlane || driver.Location == Messages.PitOut
+
+	return lap
+}
+
+func worstFlag(a Messages.FlagState, b Messages.FlagState) Messages.FlagState {
+	severity := func(flag Messages.FlagState) int {
+		switch flag {
+		case Messages.RedFlag:
+			return 3
+		case Messages.DoubleYellowFlag:
+			return 2
+		case Messages.YellowFlag:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	if severity(b) > severity(a) || (severity(a) == 0 && a == Messages.NoFlag) {
+		return b
+	}
+	return a
+}
+
+func worstSafetyCar(a Messages.TrackState, b Messages.TrackState) Messages.TrackState {
+	severity := [...]int{0, 2, 1, 4, 3}
+
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,198 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/analysis"
+)
+
+// Laps taking longer than this are behind the safety car or stopped so aren't worth comparing
+const maxLapTelemetryTime = 10 * time.Minute
+
+// Telemetry for the lap a driver is currently on
+type lapTelemetryBuffer struct {
+	lap       int
+	hasTiming bool
+	samples   []Messages.Telemetry
+}
+
+// Corners need the telemetry for each lap too
+func (p *Parser) bufferLapTelemetry() bool {
+	return p.requestedData&LapTelemetry == LapTelemetry || p.requestedData&Corners == Corners
+}
+
+func (p *Parser) LapTelemetry(driverNumber int, lap int) analysis.Lap {
+	p.lapTelemetryLock.Lock()
+	defer p.lapTelemetryLock.Unlock()
+
+	result, exists := p.lapTelemetry[driverNumber][lap]
+	if !exists {
+		return analysis.Lap{DriverNumber: driverNumber, Lap: lap}
+	}
+	return result
+}
+
+func (p *Parser) CompareLaps(firstDriver int, firstLap int, secondDriver int, secondLap int, step float64) (analysis.Comparison, error) {
+	first := p.LapTelemetry(firstDriver, firstLap)
+	if len(first.Samples) == 0 {
+		return analysis.Comparison{}, fmt.Errorf("no telemetry for car %d lap %d", firstDriver, firstLap)
+	}
+
+	second := p.LapTelemetry(secondDriver, secondLap)
+	if len(second.Samples) == 0 {
+		return analysis.Comparison{}, fmt.Errorf("no telemetry for car %d lap %d", secondDriver, secondLap)
+	}
+
+	return analysis.CompareLaps(first, second, step), nil
+}
+
+func (p *Parser) lapTelemetryBufferFor(driverNumber int) *lapTelemetryBuffer {
+	buffer, exists := p.lapTelemetryBuffers[driverNumber]
+	if !exists {
+		buffer = &lapTelemetryBuffer{}
+		p.lapTelemetryBuffers[driverNumber] = buffer
+	}
+	return buffer
+}
+
+// Only samples with a lap distance can be buffered because it is used to know which samples belong to which lap
+func (p *Parser) lapTelemetrySample(sample Messages.Telemetry) {
+	buffer := p.lapTelemetryBufferFor(sample.DriverNumber)
+	buffer.samples = append(buffer.samples, sample)
+
+	// The timing lap doesn't change for a car in the garage so don't keep more than a lap could take
+	keepFrom := sample.Timestamp.Add(-maxLapTelemetryTime)
+	x := 0
+	for x < len(buffer.samples) && buffer.samples[x].Timestamp.Before(keepFrom) {
+		x++
+	}
+	buffer.samples = buffer.samples[x:]
+}
+
+// Corners for every lap analysed for the driver
+func (p *Parser) LapCorners(driverNumber int) []Messages.LapCorners {
+	p.lapTelemetryLock.Lock()
+	defer p.lapTelemetryLock.Unlock()
+
+	result := make([]Messages.LapCorners, len(p.lapCorners[driverNumber]))
+	copy(result, p.lapCorners[driverNumber])
+	return result
+}
+
+// Replaces the corners rather than updating them because the slices are shared with the laps already returned
+func (p *Parser) renumberLapCorners(length float64) {
+	for _, laps := range p.lapCorners {
+		for x := range laps {
+			laps[x].Corners, _ = p.cornerNumbers.Number(laps[x].Corners, length)
+		}
+	}
+}
+
+// A lap is completed when the timing lap count changes. Returns the corners for any completed laps.
+func (p *Parser) lapTelemetryTiming(timing []Messages.Timing) []Messages.LapCorners {
+	result := make([]Messages.LapCorners, 0)
+
+	lapDistance := p.currentLapDistance()
+	if lapDistance == nil {
+		return result
+	}
+
+	for _, driver := range timing {
+		buffer := p.lapTelemetryBufferFor(driver.Number)
+
+		if !buffer.hasTiming {
+			buffer.hasTiming = true
+			buffer.lap = driver.Lap
+			buffer.samples = nil
+			continue
+		}
+
+		if driver.Lap == buffer.lap {
+			continue
+		}
+
+		// Only a single lap means it was driven, more is catching up on missed data
+		if driver.Lap == buffer.lap+1 {
+			samples, next := splitLapTelemetry(buffer.samples, lapDistance.Length())
+
+			p.lapTelemetryLock.Lock()
+			laps, exists := p.lapTelemetry[driver.Number]
+			if !exists {
+				laps = make(map[int]analysis.Lap)
+				p.lapTelemetry[driver.Number] = laps
+			}
+			lap := analysis.CreateLap(driver.Number, driver.Lap, samples)
+			laps[driver.Lap] = lap
+
+			numbered, added := p.cornerNumbers.Number(analysis.Corners(lap), lapDistance.Length())
+			corners := Messages.LapCorners{
+				Timestamp:    driver.Timestamp,
+				DriverNumber: driver.Number,
+				Lap:          driver.Lap,
+				Corners:      numbered,
+			}
+
+			// A new corner moves the numbers of the corners after it so keep the earlier laps matching
+			if added {
+				p.renumberLapCorners(lapDistance.Length())
+			}
+			p.lapCorners[driver.Number] = append(p.lapCorners[driver.Number], corners)
+			p.lapTelemetryLock.Unlock()
+
+			result = append(result, corners)
+
+			buffer.samples = next
+		} else {
+			buffer.samples = nil
+		}
+
+		buffer.lap = driver.Lap
+	}
+
+	return result
+}
+
+// The timing and telemetry don't change lap at the same time so use where the lap distance goes back to the start
+// to split the samples between laps. Returns the samples for the lap and any for the next lap.
+func splitLapTelemetry(samples []Messages.Telemetry, length float64) ([]Messages.Telemetry, []Messages.Telemetry) {
+	wraps := make([]int, 0)
+	for x := 1; x < len(samples); x++ {
+		if samples[x].LapDistance < samples[x-1].LapDistance-length/2 {
+			wraps = append(wraps, x)
+		}
+	}
+
+	switch len(wraps) {
+	case 0:
+		return samples, nil
+
+	case 1:
+		// Either the start is from the end of the previous lap or the end is the start of the next lap. When the
+		// samples finish near the start of a lap the timing changed after the car crossed the line.
+		if samples[0].LapDistance > length/2 && samples[len(samples)-1].LapDistance > length/2 {
+			return samples[wraps[0]:], nil
+		}
+		return samples[:wraps[0]], append([]Messages.Telemetry(nil), samples[wraps[0]:]...)
+
+	default:
+		last := wraps[len(wraps)-1]
+		return samples[wraps[len(wraps)-2]:last], append([]Messages.Telemetry(nil), samples[last:]...)
+	}
+}
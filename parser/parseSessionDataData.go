@@ -39,6 +39,7 @@ func (p *Parser) parseSessionDataData(dat map[string]interface{}, timestamp time
 				msg, exists = series.(map[string]interface{})["QualifyingPart"]
 				if exists {
 					text = fmt.Sprintf("%g", msg.(float64))
+					previousType := p.eventState.Type
 
 					switch text {
 					case "0":
@@ -52,6 +53,12 @@ func (p *Parser) parseSessionDataData(dat map[string]interface{}, timestamp time
 					default:
 						p.ParseErrorf(connection.SessionDataFile, timestamp, "SessionData: Unhandled value for QualifyingPart '%s'", text)
 					}
+
+					p.updateQualifyingPart()
+
+					if previousType != p.eventState.Type {
+						p.resetSectorFlags(Messages.NoFlag, timestamp, true)
+					}
 				}
 			}
 
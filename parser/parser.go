@@ -29,9 +29,11 @@ import (
 	"time"
 
 	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/analysis"
 	"github.com/f1gopher/f1gopherlib/connection"
 	"github.com/f1gopher/f1gopherlib/f1log"
 	"github.com/f1gopher/f1gopherlib/flowControl"
+	"github.com/f1gopher/f1gopherlib/trackMap"
 )
 
 type DataSource int
@@ -46,6 +48,17 @@ const (
 	Location
 	TeamRadio
 	Drivers
+	Laps
+	Classification
+	DriverHeadshots
+	Penalties
+	LocationFrames
+	TelemetryFrames
+	CarSamples
+	TrackMap
+	Intervals
+	LapTelemetry
+	Corners
 )
 
 type Parser struct {
@@ -55,9 +68,11 @@ type Parser struct {
 	output   flowControl.Flow
 
 	driverTimes map[string]Messages.Timing
+	driverInfo  map[string]Messages.DriverInfo
 	eventState  Messages.Event
 
-	assets connection.AssetStore
+	assets    connection.AssetStore
+	headshots chan headshotResult
 
 	session  Messages.SessionType
 	timezone *time.Location
@@ -70,9 +85,80 @@ type Parser struct {
 	sendTelemetryFor  map[int]bool
 	sendTelemetryLock sync.Mutex
 
-	trackLimitsMsgMatch       *regexp.Regexp
-	timePenaltyMsgMatch       *regexp.Regexp
-	timePenaltyServedMsgMatch *regexp.Regexp
+	telemetryChannels     map[int]Messages.TelemetryChannel
+	drsStates             map[int]Messages.CarDRSState
+	telemetryChannelsLock sync.Mutex
+
+	telemetryPolicy       TelemetryPolicy
+	telemetryPolicyStates map[int]*telemetryPolicyState
+	telemetryPolicyLock   sync.Mutex
+
+	// When each car that currently has a location last sent one
+	positionFix map[int]time.Time
+	// Location status values we have already logged as unknown
+	unknownPositionStatus map[string]bool
+
+	resampleBuffers  map[int]*resampleBuffer
+	resampleInterval time.Duration
+	resampleLock     sync.Mutex
+
+	// When the session status first changed to started
+	sessionStarted time.Time
+	catchup        bool
+
+	trackMap       *trackMap.Builder
+	trackMapSaved  bool
+	trackMapFolder string
+	trackMapTrack  string
+	trackMapYear   int
+	trackMapLock   sync.Mutex
+	// A track map was given so we don't need to build one
+	trackMapExisting bool
+
+	lapDistance     *trackMap.LapDistance
+	pitLaneDistance *trackMap.PitLaneDistance
+	lapPositions    map[int]lapPosition
+
+	intervalTraces map[int]*intervalTrace
+
+	lapTelemetryBuffers map[int]*lapTelemetryBuffer
+	lapTelemetry        map[int]map[int]analysis.Lap
+	lapTelemetryLock    sync.Mutex
+
+	lapCorners map[int][]Messages.LapCorners
+	// Numbers corners by where they are on the track
+	cornerNumbers analysis.CornerNumbers
+
+	lapTrackers    map[string]*lapTracker
+	lapHistory     map[string][]Messages.Lap
+	lapHistoryLock sync.Mutex
+
+	qualifying             qualifyingState
+	qualifyingResults      map[string]Messages.QualifyingResult
+	qualifyingTimestamp    time.Time
+	qualifyingEliminations int
+	qualifyingLock         sync.Mutex
+
+	disqualified       map[string]bool
+	classification     Messages.Classification
+	classificationLock sync.Mutex
+
+	sessionInfo     Messages.SessionInfo
+	sessionInfoLock sync.Mutex
+
+	incidents     []Messages.Incident
+	penaltiesLock sync.Mutex
+
+	sectorFlags     sectorFlagState
+	sectorFlagsLock sync.Mutex
+
+	trackLimitsMsgMatch           *regexp.Regexp
+	disqualifiedMsgMatch          *regexp.Regexp
+	penaltyDecisionMsgMatch       *regexp.Regexp
+	noFurtherActionMsgMatch       *regexp.Regexp
+	incidentInvestigationMsgMatch *regexp.Regexp
+	incidentNotedMsgMatch         *regexp.Regexp
+	incidentCarsMsgMatch          *regexp.Regexp
 }
 
 // Hardcoded shortcut for:
@@ -95,31 +181,62 @@ func Create(
 	timezone *time.Location) *Parser {
 
 	trackLimitsMatch, _ := regexp.Compile("CAR (\\d+) .* DELETED - TRACK LIMITS AT TURN")
-	timePenaltyMatch, _ := regexp.Compile("^FIA STEWARDS: (\\d+) SECOND TIME PENALTY FOR CAR (\\d+)")
-	timePenaltyServedMatch, _ := regexp.Compile("^FIA STEWARDS: PENALTY SERVED - (\\d+) SECOND TIME PENALTY FOR CAR (\\d+)")
+	disqualifiedMatch, _ := regexp.Compile("CAR (\\d+) (?:\\([A-Z]+\\) )?(?:IS )?DISQUALIFIED")
+	penaltyDecisionMatch, _ := regexp.Compile("^FIA STEWARDS: (PENALTY SERVED - )?(?:(\\d+) SECOND (TIME|STOP/GO|STOP AND GO) PENALTY|(DRIVE THROUGH) PENALTY|(\\d+) PLACE GRID PENALTY|(REPRIMAND)) FOR (?:CAR|NO\\.) ?(\\d+)")
+	noFurtherActionMatch, _ := regexp.Compile("^(?:FIA STEWARDS: )?(?:NO FURTHER ACTION - (.+?)|(.+?) (?:REVIEWED )?NO FURTHER (?:ACTION|INVESTIGATION))(?: - (.+))?$")
+	incidentInvestigationMatch, _ := regexp.Compile("^(?:FIA STEWARDS: )?(.+?) (?:UNDER INVESTIGATION|WILL BE INVESTIGATED AFTER THE (?:RACE|SESSION))(?: - (.+))?$")
+	incidentNotedMatch, _ := regexp.Compile("^(?:FIA STEWARDS: )?(.+?) NOTED(?: - (.+))?$")
+	incidentCarsMatch, _ := regexp.Compile("(?:CARS?|AND|NO\\.|,) *(\\d+)\\b")
 
 	abc := Parser{
-		ctx:                       ctx,
-		wg:                        wg,
-		requestedData:             requestedData,
-		incoming:                  incoming,
-		output:                    output,
-		driverTimes:               make(map[string]Messages.Timing),
-		assets:                    assets,
-		session:                   session,
-		timezone:                  timezone,
-		log:                       log,
-		sendTelemetryFor:          nil,
-		trackLimitsMsgMatch:       trackLimitsMatch,
-		timePenaltyMsgMatch:       timePenaltyMatch,
-		timePenaltyServedMsgMatch: timePenaltyServedMatch,
+		ctx:                           ctx,
+		wg:                            wg,
+		requestedData:                 requestedData,
+		incoming:                      incoming,
+		output:                        output,
+		driverTimes:                   make(map[string]Messages.Timing),
+		driverInfo:                    make(map[string]Messages.DriverInfo),
+		assets:                        assets,
+		headshots:                     make(chan headshotResult, 100),
+		session:                       session,
+		timezone:                      timezone,
+		log:                           log,
+		sendTelemetryFor:              nil,
+		telemetryChannels:             DefaultTelemetryChannels(),
+		drsStates:                     DefaultDRSStates(),
+		telemetryPolicy:               DefaultTelemetryPolicy(),
+		telemetryPolicyStates:         make(map[int]*telemetryPolicyState),
+		positionFix:                   make(map[int]time.Time),
+		unknownPositionStatus:         make(map[string]bool),
+		resampleBuffers:               make(map[int]*resampleBuffer),
+		resampleInterval:              DefaultResampleInterval,
+		trackMap:                      trackMap.CreateBuilder(),
+		lapPositions:                  make(map[int]lapPosition),
+		intervalTraces:                make(map[int]*intervalTrace),
+		lapTelemetryBuffers:           make(map[int]*lapTelemetryBuffer),
+		lapTelemetry:                  make(map[int]map[int]analysis.Lap),
+		lapCorners:                    make(map[int][]Messages.LapCorners),
+		lapTrackers:                   make(map[string]*lapTracker),
+		lapHistory:                    make(map[string][]Messages.Lap),
+		qualifyingResults:             make(map[string]Messages.QualifyingResult),
+		disqualified:                  make(map[string]bool),
+		sectorFlags:                   sectorFlagState{segments: make(map[int][]int), history: make(map[int][]Messages.SectorFlag)},
+		trackLimitsMsgMatch:           trackLimitsMatch,
+		disqualifiedMsgMatch:          disqualifiedMatch,
+		penaltyDecisionMsgMatch:       penaltyDecisionMatch,
+		noFurtherActionMsgMatch:       noFurtherActionMatch,
+		incidentInvestigationMsgMatch: incidentInvestigationMatch,
+		incidentNotedMsgMatch:         incidentNotedMatch,
+		incidentCarsMsgMatch:          incidentCarsMatch,
 	}
 
+	abc.output = &sessionTimeFlow{Flow: output, p: &abc}
+
 	return &abc
 }
 
 func (p *Parser) ParseErrorf(file string, timestamp time.Time, msg string, a ...any) {
-	p.log.Errorf("%s - %v: %s", file, timestamp, fmt.Sprintf(msg, a))
+	p.log.Errorf("%s - %v: %s", file, timestamp, fmt.Sprintf(msg, a...))
 }
 
 func (p *Parser) ParseTimeError(file string, timestamp time.Time, field string, err error) {
@@ -153,6 +270,12 @@ func (p *Parser) Process() {
 		case <-p.ctx.Done():
 			return
 
+		case headshot := <-p.headshots:
+			outgoing := p.updateHeadshot(headshot)
+			if p.requestedData&Drivers == Drivers && outgoing != nil {
+				p.output.AddDrivers(outgoing[0])
+			}
+
 		case msg := <-p.incoming:
 			switch msg.Name {
 			case connection.EndOfDataFile:
@@ -165,7 +288,8 @@ func (p *Parser) Process() {
 					continue
 				}
 
-				zeroTimestamp := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+				catchupTime := catchupTimestamp(dat)
+				p.catchup = true
 
 				for _, fileName := range connection.OrderedFiles {
 					if fileName == connection.TeamRadioFile ||
@@ -183,13 +307,15 @@ func (p *Parser) Process() {
 								continue
 							}
 
-							p.handleMessage(fileName, abc, zeroTimestamp)
+							p.handleMessage(fileName, abc, catchupTime)
 						} else {
-							p.handleMessage(fileName, fileData.(map[string]interface{}), zeroTimestamp)
+							p.handleMessage(fileName, fileData.(map[string]interface{}), catchupTime)
 						}
 					}
 				}
 
+				p.catchup = false
+
 			default:
 				var dat map[string]interface{}
 				var err error
@@ -219,6 +345,14 @@ func (p *Parser) Process() {
 }
 
 func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestamp time.Time) {
+	// Race control doesn't clear blue flags so check for any that have run out whatever the message is
+	expired := p.expireBlueFlags(timestamp)
+	if p.requestedData&Timing == Timing {
+		for _, driver := range expired {
+			p.output.AddTiming(driver)
+		}
+	}
+
 	switch name {
 	case connection.WeatherDataFile:
 		if p.requestedData&Weather == Weather {
@@ -239,19 +373,43 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 		}
 
 	case connection.TimingDataFile:
-		if p.requestedData&Timing == Timing {
-			outgoing, err := p.parseTimingData(dat, timestamp)
+		if p.requestedData&Timing == Timing || p.requestedData&Laps == Laps || p.requestedData&TrackMap == TrackMap ||
+			p.requestedData&Intervals == Intervals || p.requestedData&Classification == Classification ||
+			p.bufferLapTelemetry() || p.buildTrackMap() {
+			outgoing, lapOutgoing, err := p.parseTimingData(dat, timestamp)
 			if err == nil {
-				for _, rcMsg := range outgoing {
-					p.output.AddTiming(rcMsg)
+				if p.requestedData&Timing == Timing {
+					for _, rcMsg := range outgoing {
+						p.output.AddTiming(rcMsg)
+					}
+				}
+
+				if p.buildTrackMap() {
+					p.trackMapTiming(outgoing)
+				}
+
+				if p.bufferLapTelemetry() {
+					corners := p.lapTelemetryTiming(outgoing)
+
+					if p.requestedData&Corners == Corners {
+						for _, lap := range corners {
+							p.output.AddLapCorners(lap)
+						}
+					}
+				}
+
+				if p.requestedData&Laps == Laps {
+					for _, lap := range lapOutgoing {
+						p.output.AddLap(lap)
+					}
 				}
 			}
 		}
 
 	case connection.TimingAppDataFile:
-		if p.requestedData&Timing == Timing {
+		if p.requestedData&Timing == Timing || p.requestedData&Laps == Laps {
 			outgoing, err := p.parseTimingAppData(dat, timestamp)
-			if err == nil {
+			if err == nil && p.requestedData&Timing == Timing {
 				for _, rcMsg := range outgoing {
 					p.output.AddTiming(rcMsg)
 				}
@@ -267,7 +425,9 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 		}
 
 	case connection.CarDataFile:
-		if p.requestedData&Telemetry == Telemetry || p.requestedData&Timing == Timing {
+		if p.requestedData&Telemetry == Telemetry || p.requestedData&TelemetryFrames == TelemetryFrames ||
+			p.requestedData&CarSamples == CarSamples || p.requestedData&Timing == Timing ||
+			p.bufferLapTelemetry() {
 			outgoing, timingOutgoing, err := p.parseCarData(dat, timestamp)
 			if err == nil {
 				if p.requestedData&Telemetry == Telemetry {
@@ -276,6 +436,20 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 					}
 				}
 
+				if p.requestedData&TelemetryFrames == TelemetryFrames {
+					for _, frame := range telemetryFrames(outgoing) {
+						p.output.AddTelemetryFrame(frame)
+					}
+				}
+
+				if p.requestedData&CarSamples == CarSamples {
+					samples := p.resample()
+					p.carSampleLapDistances(samples)
+					for _, sample := range samples {
+						p.output.AddCarSample(sample)
+					}
+				}
+
 				if p.requestedData&Timing == Timing {
 					for _, rcMsg := range timingOutgoing {
 						p.output.AddTiming(rcMsg)
@@ -285,40 +459,71 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 		}
 
 	case connection.PositionFile:
-		if p.requestedData&Location == Location {
+		// Telemetry needs the locations too to work out its lap distance
+		if p.needsLapDistance() {
 			outgoing, err := p.parsePositionData(dat, timestamp)
 			if err == nil {
-				for _, rcMsg := range outgoing {
-					p.output.AddLocation(rcMsg)
+				if p.buildTrackMap() {
+					p.trackMapLocations(outgoing)
+				}
+				p.locationLapDistances(outgoing)
+
+				if p.requestedData&Intervals == Intervals {
+					for _, intervals := range p.intervals(outgoing) {
+						p.output.AddIntervals(intervals)
+					}
+				}
+
+				if p.requestedData&Location == Location {
+					for _, rcMsg := range outgoing {
+						p.output.AddLocation(rcMsg)
+					}
+				}
+
+				if p.requestedData&LocationFrames == LocationFrames {
+					for _, frame := range locationFrames(outgoing) {
+						p.output.AddLocationFrame(frame)
+					}
+				}
+
+				if p.requestedData&CarSamples == CarSamples {
+					for _, location := range outgoing {
+						p.resampleAddLocation(location)
+					}
+
+					samples := p.resample()
+					p.carSampleLapDistances(samples)
+					for _, sample := range samples {
+						p.output.AddCarSample(sample)
+					}
 				}
 			}
 		}
 
 	case connection.SessionInfoFile:
-		if p.requestedData&Event == Event {
-			outgoing, timingOutgoing, err := p.parseSessionInfoData(dat, timestamp)
-			if err == nil {
-				p.output.AddEvent(outgoing)
-			}
+		// Always parse so that the session info can be queried whatever data has been requested
+		outgoing, timingOutgoing, err := p.parseSessionInfoData(dat, timestamp)
+		if p.requestedData&Event == Event && err == nil {
+			p.output.AddEvent(outgoing)
+		}
 
-			if p.requestedData&Timing == Timing {
-				for _, rcMsg := range timingOutgoing {
-					p.output.AddTiming(rcMsg)
-				}
+		if p.requestedData&Event == Event && p.requestedData&Timing == Timing {
+			for _, rcMsg := range timingOutgoing {
+				p.output.AddTiming(rcMsg)
 			}
 		}
 
 	case connection.LapCountFile:
-		if p.requestedData&Event == Event {
-			outgoing, err := p.parseCurrentLapData(dat, timestamp)
-			if err == nil {
-				p.output.AddEvent(outgoing)
-			}
+		// Always parse so that every message has the leader lap whatever data has been requested
+		outgoing, err := p.parseCurrentLapData(dat, timestamp)
+		if p.requestedData&Event == Event && err == nil {
+			p.output.AddEvent(outgoing)
 		}
 
 	case connection.RaceControlMessagesFile:
-		if p.requestedData&RaceControl == RaceControl || p.requestedData&Event == Event || p.requestedData&Timing == Timing {
-			outgoingRcm, outgoingEvent, outgoingTiming, err := p.parseRaceControlMessagesData(dat, timestamp)
+		if p.requestedData&RaceControl == RaceControl || p.requestedData&Event == Event || p.requestedData&Timing == Timing ||
+			p.requestedData&Laps == Laps || p.requestedData&Penalties == Penalties || p.requestedData&Classification == Classification {
+			outgoingRcm, outgoingEvent, outgoingTiming, outgoingPenalties, err := p.parseRaceControlMessagesData(dat, timestamp)
 			if err == nil {
 
 				if p.requestedData&RaceControl == RaceControl {
@@ -338,15 +543,27 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 						p.output.AddTiming(timingMsg)
 					}
 				}
+
+				if p.requestedData&Penalties == Penalties {
+					for _, penalty := range outgoingPenalties {
+						p.output.AddPenalty(penalty)
+					}
+				}
 			}
 		}
 
 	case connection.SessionStatusFile:
-		outgoing, err := p.parseSessionStatusData(dat, timestamp)
+		outgoing, classificationOutgoing, err := p.parseSessionStatusData(dat, timestamp)
 		if p.requestedData&Event == Event && err == nil {
 			p.output.AddEvent(outgoing)
 		}
 
+		if p.requestedData&Classification == Classification && err == nil {
+			for _, classification := range classificationOutgoing {
+				p.output.AddClassification(classification)
+			}
+		}
+
 	case connection.TeamRadioFile:
 		if p.requestedData&TeamRadio == TeamRadio {
 			outgoing, err := p.parseTeamRadioData(dat, timestamp)
@@ -360,7 +577,7 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 	case connection.DriverListFile:
 		outgoing := p.parseDriverList(dat, timestamp)
 		if p.requestedData&Drivers == Drivers && outgoing != nil {
-			// Is always only one record
+			// Is always only one record with the full list of drivers
 			p.output.AddDrivers(outgoing[0])
 		}
 
@@ -370,9 +587,18 @@ func (p *Parser) handleMessage(name string, dat map[string]interface{}, timestam
 			p.output.AddEvent(outgoing)
 		}
 
+	case connection.TimingStatsFile:
+		if p.requestedData&Timing == Timing {
+			outgoing, err := p.parseTimingStatsData(dat, timestamp)
+			if err == nil {
+				for _, rcMsg := range outgoing {
+					p.output.AddTiming(rcMsg)
+				}
+			}
+		}
+
 	case connection.TrackStatusFile:
 	case connection.TopThreeFile:
-	case connection.TimingStatsFile:
 	case connection.AudioStreamsFile:
 	case connection.ContentStreamsFile:
 
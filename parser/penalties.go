@@ -0,0 +1,338 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+func (p *Parser) PenaltySummary(driverNumber int) Messages.PenaltySummary {
+	p.penaltiesLock.Lock()
+	defer p.penaltiesLock.Unlock()
+
+	summary := Messages.PenaltySummary{
+		DriverNumber: driverNumber,
+		Incidents:    make([]Messages.Incident, 0),
+	}
+
+	for _, incident := range p.incidents {
+		if !incidentInvolves(incident, driverNumber) {
+			continue
+		}
+
+		summary.Incidents = append(summary.Incidents, incident)
+
+		if incident.State == Messages.IncidentNoted || incident.State == Messages.IncidentUnderInvestigation {
+			summary.UnderInvestigation++
+			continue
+		}
+
+		if incident.Penalty == Messages.NoFurtherAction {
+			summary.NoFurtherActions++
+			continue
+		}
+
+		// Other cars involved didn't get the penalty
+		if incident.Penalised != driverNumber {
+			continue
+		}
+
+		switch incident.Penalty {
+		case Messages.TimePenalty:
+			if incident.State == Messages.IncidentServed {
+				summary.ServedTimePenaltySeconds += incident.Seconds
+			} else {
+				summary.TimePenaltySeconds += incident.Seconds
+			}
+		case Messages.DriveThroughPenalty:
+			summary.DriveThroughs++
+		case Messages.StopGoPenalty:
+			summary.StopGos++
+		case Messages.GridPenalty:
+			summary.GridPlaces += incident.GridPlaces
+		case Messages.Reprimand:
+			summary.Reprimands++
+		case Messages.BlackAndWhiteFlagPenalty:
+			summary.BlackAndWhiteFlags++
+		}
+	}
+
+	return summary
+}
+
+// Incidents are only for the session they happened in
+func (p *Parser) resetPenalties() {
+	p.penaltiesLock.Lock()
+	p.incidents = nil
+	p.penaltiesLock.Unlock()
+}
+
+func incidentInvolves(incident Messages.Incident, driverNumber int) bool {
+	if incident.Penalised == driverNumber {
+		return true
+	}
+
+	for _, car := range incident.Cars {
+		if car == driverNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// Work out what stage of the stewards process the message is for and update the matching incident
+func (p *Parser) readStewardsMessage(
+	rcm Messages.RaceControlMessage,
+	penaltyResult *[]Messages.Incident,
+	timingResult *[]Messages.Timing) {
+
+	p.penaltiesLock.Lock()
+	defer p.penaltiesLock.Unlock()
+
+	if rcm.Flag == Messages.BlackAndWhite && rcm.RacingNumber > 0 {
+		index := p.newIncident(rcm, Messages.IncidentDecided, stewardsText(rcm.Msg), []int{rcm.RacingNumber})
+		p.incidents[index].Penalty = Messages.BlackAndWhiteFlagPenalty
+		p.incidents[index].Penalised = rcm.RacingNumber
+		*penaltyResult = append(*penaltyResult, p.incidents[index])
+		return
+	}
+
+	if rcm.Category != Messages.OtherCategory {
+		return
+	}
+
+	text := stewardsText(rcm.Msg)
+
+	matches := p.penaltyDecisionMsgMatch.FindStringSubmatch(rcm.Msg)
+	if len(matches) == 8 {
+		p.readPenaltyDecision(rcm, text, matches, penaltyResult, timingResult)
+		return
+	}
+
+	matches = p.noFurtherActionMsgMatch.FindStringSubmatch(rcm.Msg)
+	if len(matches) == 4 {
+		description := matches[1] + matches[2]
+		index := p.findOpenIncident(description, p.incidentCars(description), "")
+		if index == -1 {
+			index = p.newIncident(rcm, Messages.IncidentDecided, text, p.incidentCars(description))
+			p.incidents[index].Description = description
+			p.incidents[index].Reason = matches[3]
+		} else {
+			p.updateIncident(index, rcm, Messages.IncidentDecided)
+		}
+		p.incidents[index].Penalty = Messages.NoFurtherAction
+		*penaltyResult = append(*penaltyResult, p.incidents[index])
+		return
+	}
+
+	matches = p.incidentInvestigationMsgMatch.FindStringSubmatch(rcm.Msg)
+	if len(matches) == 3 {
+		index := p.findOpenIncident(matches[1], nil, "")
+		if index == -1 {
+			index = p.newIncident(rcm, Messages.IncidentUnderInvestigation, text, p.incidentCars(matches[1]))
+			p.incidents[index].Description = matches[1]
+			p.incidents[index].Reason = matches[2]
+		} else {
+			p.updateIncident(index, rcm, Messages.IncidentUnderInvestigation)
+		}
+		*penaltyResult = append(*penaltyResult, p.incidents[index])
+		return
+	}
+
+	matches = p.incidentNotedMsgMatch.FindStringSubmatch(rcm.Msg)
+	if len(matches) == 3 {
+		index := p.newIncident(rcm, Messages.IncidentNoted, text, p.incidentCars(matches[1]))
+		p.incidents[index].Description = matches[1]
+		p.incidents[index].Reason = matches[2]
+		*penaltyResult = append(*penaltyResult, p.incidents[index])
+	}
+}
+
+func (p *Parser) readPenaltyDecision(
+	rcm Messages.RaceControlMessage,
+	text string,
+	matches []string,
+	penaltyResult *[]Messages.Incident,
+	timingResult *[]Messages.Timing) {
+
+	served := len(matches[1]) > 0
+	seconds, _ := strconv.Atoi(matches[2])
+	gridPlaces, _ := strconv.Atoi(matches[5])
+	car, _ := strconv.Atoi(matches[7])
+
+	penalty := Messages.NoPenalty
+	switch {
+	case matches[3] == "TIME":
+		penalty = Messages.TimePenalty
+	case len(matches[3]) > 0:
+		penalty = Messages.StopGoPenalty
+	case len(matches[4]) > 0:
+		penalty = Messages.DriveThroughPenalty
+	case len(matches[5]) > 0:
+		penalty = Messages.GridPenalty
+	case len(matches[6]) > 0:
+		penalty = Messages.Reprimand
+	}
+
+	reason := ""
+	if separator := strings.Index(text, " - "); separator != -1 {
+		reason = text[separator+3:]
+	}
+
+	index := -1
+	if served {
+		for x := len(p.incidents) - 1; x >= 0; x-- {
+			incident := p.incidents[x]
+			if incident.State == Messages.IncidentDecided &&
+				incident.Penalised == car &&
+				incident.Penalty == penalty &&
+				incident.Seconds == seconds {
+				index = x
+				break
+			}
+		}
+
+		if index == -1 {
+			index = p.newIncident(rcm, Messages.IncidentServed, text, []int{car})
+		} else {
+			p.updateIncident(index, rcm, Messages.IncidentServed)
+		}
+	} else {
+		index = p.findOpenIncident("", []int{car}, reason)
+		if index == -1 {
+			index = p.newIncident(rcm, Messages.IncidentDecided, text, []int{car})
+			p.incidents[index].Reason = reason
+		} else {
+			p.updateIncident(index, rcm, Messages.IncidentDecided)
+		}
+	}
+
+	p.incidents[index].Penalty = penalty
+	p.incidents[index].Penalised = car
+	p.incidents[index].Seconds = seconds
+	p.incidents[index].GridPlaces = gridPlaces
+	*penaltyResult = append(*penaltyResult, p.incidents[index])
+
+	if penalty != Messages.TimePenalty {
+		return
+	}
+
+	currentDriver, exists := p.driverTimes[matches[7]]
+	if exists {
+		if served {
+			currentDriver.TimePenaltySeconds -= seconds
+		} else {
+			currentDriver.TimePenaltySeconds += seconds
+		}
+		*timingResult = append(*timingResult, currentDriver)
+		p.driverTimes[matches[7]] = currentDriver
+	}
+}
+
+func (p *Parser) newIncident(
+	rcm Messages.RaceControlMessage,
+	state Messages.IncidentState,
+	description string,
+	cars []int) int {
+
+	lap := rcm.Lap
+	if lap == 0 {
+		lap = p.eventState.CurrentLap
+	}
+
+	p.incidents = append(p.incidents, Messages.Incident{
+		Timestamp:   rcm.Timestamp,
+		Id:          len(p.incidents) + 1,
+		State:       state,
+		Lap:         lap,
+		Description: description,
+		Cars:        cars,
+		Messages:    []string{rcm.Msg},
+	})
+
+	return len(p.incidents) - 1
+}
+
+func (p *Parser) updateIncident(index int, rcm Messages.RaceControlMessage, state Messages.IncidentState) {
+	incident := &p.incidents[index]
+	incident.Timestamp = rcm.Timestamp
+	incident.State = state
+
+	// Copy so that incidents already sent don't change
+	history := make([]string, len(incident.Messages), len(incident.Messages)+1)
+	copy(history, incident.Messages)
+	incident.Messages = append(history, rcm.Msg)
+}
+
+// Find the most recent incident without a decision that matches the description or, failing that, involves one of the
+// cars for the same reason or just involves one of the cars
+func (p *Parser) findOpenIncident(description string, cars []int, reason string) int {
+	isOpen := func(incident Messages.Incident) bool {
+		return incident.State == Messages.IncidentNoted || incident.State == Messages.IncidentUnderInvestigation
+	}
+
+	involves := func(incident Messages.Incident) bool {
+		for _, car := range cars {
+			if incidentInvolves(incident, car) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(description) > 0 {
+		for x := len(p.incidents) - 1; x >= 0; x-- {
+			if isOpen(p.incidents[x]) && p.incidents[x].Description == description {
+				return x
+			}
+		}
+	}
+
+	if len(reason) > 0 {
+		for x := len(p.incidents) - 1; x >= 0; x-- {
+			if isOpen(p.incidents[x]) && p.incidents[x].Reason == reason && involves(p.incidents[x]) {
+				return x
+			}
+		}
+	}
+
+	for x := len(p.incidents) - 1; x >= 0; x-- {
+		if isOpen(p.incidents[x]) && involves(p.incidents[x]) {
+			return x
+		}
+	}
+
+	return -1
+}
+
+func (p *Parser) incidentCars(text string) []int {
+	cars := make([]int, 0)
+	for _, match := range p.incidentCarsMsgMatch.FindAllStringSubmatch(text, -1) {
+		car, err := strconv.Atoi(match[1])
+		if err == nil {
+			cars = append(cars, car)
+		}
+	}
+	return cars
+}
+
+func stewardsText(msg string) string {
+	text := strings.TrimPrefix(msg, "FIA STEWARDS: ")
+	return strings.TrimPrefix(text, "PENALTY SERVED - ")
+}
@@ -19,10 +19,14 @@ import (
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"github.com/f1gopher/f1gopherlib/connection"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 )
 
+// A car that hasn't sent a location for this long has lost its location
+const positionFixTimeout = 5 * time.Second
+
 func (p *Parser) parsePositionData(dat map[string]interface{}, timestamp time.Time) ([]Messages.Location, error) {
 
 	result := make([]Messages.Location, 0)
@@ -37,26 +41,76 @@ func (p *Parser) parsePositionData(dat map[string]interface{}, timestamp time.Ti
 
 		for key, entry := range record.(map[string]interface{})["Entries"].(map[string]interface{}) {
 			driver, _ := strconv.ParseInt(key, 10, 8)
-			//status := entry.(map[string]interface{})["Status"].(string)
+			status, _ := entry.(map[string]interface{})["Status"].(string)
 
 			x := entry.(map[string]interface{})["X"].(float64)
 			y := entry.(map[string]interface{})["Y"].(float64)
 			z := entry.(map[string]interface{})["Z"].(float64)
 
-			// Ignore locations which are (0, 0) because it means we don't have a location for them
+			// Locations which are (0, 0) mean we don't have a location for them so only send one to say we have
+			// lost the location for a car that had one
 			if math.Abs(x) < tolerance && math.Abs(y) < tolerance {
+				if _, exists := p.positionFix[int(driver)]; exists {
+					delete(p.positionFix, int(driver))
+					result = append(result, noFixLocation(int(driver), dataTimestamp))
+				}
 				continue
 			}
+			p.positionFix[int(driver)] = dataTimestamp
 
-			result = append(result, Messages.Location{
+			location := Messages.Location{
 				Timestamp:    dataTimestamp,
 				DriverNumber: int(driver),
 				X:            x,
 				Y:            y,
 				Z:            z,
-			})
+			}
+
+			switch status {
+			case "OnTrack", "":
+				// Treat a missing status as on track
+				location.Status = Messages.PositionOnTrack
+			case "OffTrack":
+				location.Status = Messages.PositionOffTrack
+			default:
+				// Every car sends several locations a second so only log each unknown value once
+				if !p.unknownPositionStatus[status] {
+					p.unknownPositionStatus[status] = true
+					p.ParseErrorf(connection.PositionFile, timestamp, "Position: Unhandled status '%s'", status)
+				}
+			}
+
+			result = append(result, location)
 		}
+
+		// Cars can also stop being sent at all
+		result = append(result, p.expirePositionFixes(dataTimestamp)...)
 	}
 
 	return result, nil
 }
+
+func (p *Parser) expirePositionFixes(timestamp time.Time) []Messages.Location {
+	var expired []int
+	for driver, lastFix := range p.positionFix {
+		if timestamp.Sub(lastFix) >= positionFixTimeout {
+			expired = append(expired, driver)
+		}
+	}
+	sort.Ints(expired)
+
+	result := make([]Messages.Location, 0, len(expired))
+	for _, driver := range expired {
+		delete(p.positionFix, driver)
+		result = append(result, noFixLocation(driver, timestamp))
+	}
+	return result
+}
+
+func noFixLocation(driver int, timestamp time.Time) Messages.Location {
+	return Messages.Location{
+		Timestamp:    timestamp,
+		DriverNumber: driver,
+		Status:       Messages.PositionNoFix,
+	}
+}
@@ -0,0 +1,287 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+)
+
+type qualifyingState struct {
+	sprintQualifying bool
+	part             int
+	cutOffTime       time.Duration
+
+	// Number of drivers taking part in each part
+	entries []int
+}
+
+func (p *Parser) QualifyingResults() Messages.QualifyingClassification {
+	p.qualifyingLock.Lock()
+	defer p.qualifyingLock.Unlock()
+
+	result := Messages.QualifyingClassification{
+		Timestamp:        p.qualifyingTimestamp,
+		SprintQualifying: p.qualifying.sprintQualifying,
+		Part:             p.qualifying.part,
+		CutOffTime:       p.qualifying.cutOffTime,
+		Results:          make([]Messages.QualifyingResult, 0, len(p.qualifyingResults)),
+	}
+
+	for _, driver := range p.qualifyingResults {
+		result.Results = append(result.Results, driver)
+	}
+
+	// Drivers who got further are ahead and then order by time in the last part they took part in
+	reached := func(driver Messages.QualifyingResult) int {
+		if driver.KnockedOutInPart > 0 {
+			return driver.KnockedOutInPart
+		}
+		return max(result.Part, 1)
+	}
+
+	sort.SliceStable(result.Results, func(i, j int) bool {
+		a := result.Results[i]
+		b := result.Results[j]
+
+		if reached(a) != reached(b) {
+			return reached(a) > reached(b)
+		}
+
+		for part := reached(a) - 1; part >= 0; part-- {
+			if a.Times[part] == b.Times[part] {
+				continue
+			}
+
+			// No time goes to the back
+			if a.Times[part] == 0 || b.Times[part] == 0 {
+				return b.Times[part] == 0
+			}
+
+			return a.Times[part] < b.Times[part]
+		}
+
+		return a.Number < b.Number
+	})
+
+	for x := range result.Results {
+		result.Results[x].Position = x + 1
+	}
+
+	// If the feed doesn't tell us the cut off then it is the time of the last driver who will get through
+	part := result.Part
+	if result.CutOffTime == 0 && part > 0 && part < len(p.qualifying.entries) {
+		through := p.qualifying.entries[part]
+		if through > 0 && through <= len(result.Results) {
+			result.CutOffTime = result.Results[through-1].Times[part-1]
+		}
+	}
+
+	return result
+}
+
+func (p *Parser) qualifyingPart() int {
+	switch p.eventState.Type {
+	case Messages.Qualifying1:
+		return 1
+	case Messages.Qualifying2:
+		return 2
+	case Messages.Qualifying3:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (p *Parser) parseQualifyingSessionData(dat map[string]interface{}, timestamp time.Time) {
+	p.qualifyingLock.Lock()
+	defer p.qualifyingLock.Unlock()
+
+	cutOff, exists := dat["CutOffTime"].(string)
+	if exists {
+		var err error
+		p.qualifying.cutOffTime = 0
+		if len(cutOff) > 0 {
+			p.qualifying.cutOffTime, err = parseDuration(cutOff)
+			if err != nil {
+				p.ParseTimeError(connection.TimingDataFile, timestamp, "CutOffTime", err)
+			}
+		}
+	}
+
+	entries, exists := dat["NoEntries"].([]interface{})
+	if exists {
+		p.qualifying.entries = make([]int, 0, len(entries))
+		for _, count := range entries {
+			value, _ := count.(float64)
+			p.qualifying.entries = append(p.qualifying.entries, int(value))
+		}
+	}
+}
+
+func (p *Parser) updateQualifyingPart() {
+	p.qualifyingLock.Lock()
+	defer p.qualifyingLock.Unlock()
+
+	p.qualifying.part = p.qualifyingPart()
+	p.qualifying.sprintQualifying = p.eventState.SprintQualifying
+}
+
+func (p *Parser) readBestLapTimes(bestLapTimes interface{}, driver *Messages.Timing, timestamp time.Time) {
+	readPart := func(part int, data interface{}) {
+		if part < 0 || part >= Messages.MaxQualifyingParts {
+			p.ParseErrorf(connection.TimingDataFile, timestamp, "Unhandled qualifying part %d", part)
+			return
+		}
+
+		value, exists := data.(map[string]interface{})["Value"].(string)
+		if !exists {
+			return
+		}
+
+		var lapTime time.Duration
+		var err error
+		if len(value) > 0 {
+			lapTime, err = parseDuration(value)
+			if err != nil {
+				p.ParseTimeError(connection.TimingDataFile, timestamp, "BestLapTimes Value", err)
+				return
+			}
+		}
+		driver.QualifyingTimes[part] = lapTime
+	}
+
+	switch bestLapTimes.(type) {
+	case []interface{}:
+		for part, data := range bestLapTimes.([]interface{}) {
+			readPart(part, data)
+		}
+
+	case map[string]interface{}:
+		for key, data := range bestLapTimes.(map[string]interface{}) {
+			part, err := strconv.Atoi(key)
+			if err != nil {
+				p.ParseErrorf(connection.TimingDataFile, timestamp, "Unhandled qualifying part '%s'", key)
+				continue
+			}
+			readPart(part, data)
+		}
+
+	default:
+		p.ParseErrorf(connection.TimingDataFile, timestamp, "Unhandled BestLapTimes format: %v", bestLapTimes)
+	}
+}
+
+func (p *Parser) knockedOutOfQualifying(driver *Messages.Timing, knockedOut bool) {
+	if knockedOut && !driver.KnockedOutOfQualifying {
+		// The elimination order is filled in once the whole update has been read
+		driver.KnockedOutInPart = p.knockedOutPart(driver.Position)
+		driver.EliminationOrder = 0
+	} else if !knockedOut {
+		driver.KnockedOutInPart = 0
+		driver.EliminationOrder = 0
+	}
+
+	driver.KnockedOutOfQualifying = knockedOut
+}
+
+// The knocked out flag can arrive after the next part has started so use the number of cars going through to each
+// part to work out which part the car was knocked out in
+func (p *Parser) knockedOutPart(position int) int {
+	part := max(p.qualifyingPart(), 1)
+	if position <= 0 {
+		return part
+	}
+
+	for x := 1; x < part && x < len(p.qualifying.entries); x++ {
+		if p.qualifying.entries[x] > 0 && position > p.qualifying.entries[x] {
+			return x
+		}
+	}
+
+	return part
+}
+
+// Cars knocked out in the same update are numbered by position, slowest first, so the order doesn't depend on the
+// order we read them in
+func (p *Parser) numberEliminations(drivers []Messages.Timing, timestamp time.Time) {
+	eliminated := make([]int, 0)
+	for x := range drivers {
+		if drivers[x].KnockedOutOfQualifying && drivers[x].EliminationOrder == 0 {
+			eliminated = append(eliminated, x)
+		}
+	}
+
+	position := func(driver Messages.Timing) int {
+		if driver.Position <= 0 {
+			return math.MaxInt
+		}
+		return driver.Position
+	}
+
+	sort.SliceStable(eliminated, func(i, j int) bool {
+		a := drivers[eliminated[i]]
+		b := drivers[eliminated[j]]
+
+		if position(a) != position(b) {
+			return position(a) > position(b)
+		}
+		return a.Number < b.Number
+	})
+
+	for _, x := range eliminated {
+		p.qualifyingEliminations++
+		drivers[x].EliminationOrder = p.qualifyingEliminations
+		p.driverTimes[strconv.Itoa(drivers[x].Number)] = drivers[x]
+		p.updateQualifyingResult(&drivers[x], timestamp)
+	}
+}
+
+// Clear everything from the previous session
+func (p *Parser) resetQualifying() {
+	p.qualifyingLock.Lock()
+	defer p.qualifyingLock.Unlock()
+
+	p.qualifying = qualifyingState{}
+	p.qualifyingResults = make(map[string]Messages.QualifyingResult)
+	p.qualifyingTimestamp = time.Time{}
+	p.qualifyingEliminations = 0
+}
+
+func (p *Parser) updateQualifyingResult(driver *Messages.Timing, timestamp time.Time) {
+	if p.qualifyingPart() == 0 {
+		return
+	}
+
+	p.qualifyingLock.Lock()
+	defer p.qualifyingLock.Unlock()
+
+	p.qualifyingTimestamp = timestamp
+	p.qualifyingResults[strconv.Itoa(driver.Number)] = Messages.QualifyingResult{
+		Number:           driver.Number,
+		Name:             driver.Name,
+		ShortName:        driver.ShortName,
+		Team:             driver.Team,
+		Times:            driver.QualifyingTimes,
+		KnockedOutInPart: driver.KnockedOutInPart,
+		EliminationOrder: driver.EliminationOrder,
+	}
+}
@@ -17,32 +17,32 @@ package parser
 
 import (
 	"reflect"
-	"strconv"
 	"time"
 
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"github.com/f1gopher/f1gopherlib/connection"
 )
 
-func (p *Parser) parseRaceControlMessagesData(dat map[string]interface{}, timestamp time.Time) ([]Messages.RaceControlMessage, []Messages.Event, []Messages.Timing, error) {
+func (p *Parser) parseRaceControlMessagesData(dat map[string]interface{}, timestamp time.Time) ([]Messages.RaceControlMessage, []Messages.Event, []Messages.Timing, []Messages.Incident, error) {
 
 	result := make([]Messages.RaceControlMessage, 0)
 	eventResult := make([]Messages.Event, 0)
 	timingResult := make([]Messages.Timing, 0)
+	penaltyResult := make([]Messages.Incident, 0)
 
 	if reflect.TypeOf(dat["Messages"]).Kind() == reflect.Slice {
 		for _, msg := range dat["Messages"].([]interface{}) {
-			p.readRaceControlMessage(msg, timestamp, &result, &eventResult, &timingResult)
+			p.readRaceControlMessage(msg, timestamp, &result, &eventResult, &timingResult, &penaltyResult)
 		}
 	} else if reflect.TypeOf(dat["Messages"]).Kind() == reflect.Map {
 		for _, msg := range dat["Messages"].(map[string]interface{}) {
-			p.readRaceControlMessage(msg, timestamp, &result, &eventResult, &timingResult)
+			p.readRaceControlMessage(msg, timestamp, &result, &eventResult, &timingResult, &penaltyResult)
 		}
 	} else {
 		p.ParseErrorf(connection.RaceControlMessagesFile, timestamp, "Unhandled data format: %v", dat)
 	}
 
-	return result, eventResult, timingResult, nil
+	return result, eventResult, timingResult, penaltyResult, nil
 }
 
 func (p *Parser) readRaceControlMessage(
@@ -50,7 +50,8 @@ func (p *Parser) readRaceControlMessage(
 	timestamp time.Time,
 	result *[]Messages.RaceControlMessage,
 	eventResult *[]Messages.Event,
-	timingResult *[]Messages.Timing) {
+	timingResult *[]Messages.Timing,
+	penaltyResult *[]Messages.Incident) {
 
 	time, err := parseTime(msg.(map[string]interface{})["Utc"].(string))
 	if err != nil {
@@ -58,14 +59,16 @@ func (p *Parser) readRaceControlMessage(
 		return
 	}
 
-	// Lap
-	// Category
-	status := msg.(map[string]interface{})["Message"].(string)
-	category := msg.(map[string]interface{})["Category"].(string)
+	if p.expireSectorFlags(time) {
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+	}
 
-	//status := msg.(map[string]interface{})["Message"].(string)
+	record := msg.(map[string]interface{})
+	status := record["Message"].(string)
+	category, _ := record["Category"].(string)
 
-	flagTxt, exists := msg.(map[string]interface{})["Flag"].(string)
+	flagTxt, exists := record["Flag"].(string)
 	flag := Messages.NoFlag
 	if exists {
 		switch flagTxt {
@@ -86,6 +89,10 @@ func (p *Parser) readRaceControlMessage(
 		}
 	}
 
+	rcm := p.readRaceControlFields(record, category, time)
+	rcm.Msg = status
+	rcm.Flag = flag
+
 	// Handle stward penalty messages messages
 	if category == "Other" {
 		// Track limit warning
@@ -99,148 +106,72 @@ func (p *Parser) readRaceControlMessage(
 			}
 		}
 
-		// Time penalty being given
-		matches = p.timePenaltyMsgMatch.FindStringSubmatch(status)
-		if len(matches) == 3 {
-			seconds, _ := strconv.Atoi(matches[1])
-
-			currentDriver, exists := p.driverTimes[matches[2]]
-			if exists {
-				currentDriver.TimePenaltySeconds += seconds
-				*timingResult = append(*timingResult, currentDriver)
-				p.driverTimes[matches[1]] = currentDriver
-			}
-		}
-
-		// Time penalty being served
-		matches = p.timePenaltyServedMsgMatch.FindStringSubmatch(status)
-		if len(matches) == 3 {
-			seconds, _ := strconv.Atoi(matches[1])
-			currentDriver, exists := p.driverTimes[matches[2]]
-			if exists {
-				currentDriver.TimePenaltySeconds -= seconds
-				*timingResult = append(*timingResult, currentDriver)
-				p.driverTimes[matches[1]] = currentDriver
-			}
+		// Disqualification
+		matches = p.disqualifiedMsgMatch.FindStringSubmatch(status)
+		if len(matches) == 2 {
+			p.driverDisqualified(matches[1])
 		}
 	}
 
-	*result = append(*result, Messages.RaceControlMessage{
-		Timestamp: time,
-		Msg:       status,
-		Flag:      flag,
-	})
-
-	switch status {
-	case "GREEN LIGHT - PIT EXIT OPEN":
-		p.eventState.PitExitOpen = true
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "RED LIGHT - PIT EXIT CLOSED":
-		p.eventState.PitExitOpen = false
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "VIRTUAL SAFETY CAR DEPLOYED":
-		p.eventState.SafetyCar = Messages.VirtualSafetyCar
-		p.eventState.Timestamp = time
-		p.eventState.DRSEnabled = Messages.DRSDisabled
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "VIRTUAL SAFETY CAR ENDING":
-		p.eventState.SafetyCar = Messages.VirtualSafetyCarEnding
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "SAFETY CAR DEPLOYED":
-		p.eventState.SafetyCar = Messages.SafetyCar
-		p.eventState.Timestamp = time
-		p.eventState.DRSEnabled = Messages.DRSDisabled
-		*eventResult = append(*eventResult, p.eventState)
+	p.readStewardsMessage(rcm, penaltyResult, timingResult)
+	p.readDriverFlag(rcm, timingResult)
 
-	case "SAFETY CAR IN THIS LAP":
-		p.eventState.SafetyCar = Messages.SafetyCarEnding
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
+	*result = append(*result, rcm)
 
-	case "DRS ENABLED":
-		p.eventState.DRSEnabled = Messages.DRSEnabled
+	// Use the structured fields when we have them and fallback to the message text for older data
+	if p.updateEventFromRaceControl(rcm) {
 		p.eventState.Timestamp = time
 		*eventResult = append(*eventResult, p.eventState)
-
-	case "DRS DISABLED":
-		p.eventState.DRSEnabled = Messages.DRSDisabled
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "OVERTAKE ENABLED":
-		p.eventState.OvertakeEnabled = Messages.OvertakeEnabled
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-	case "OVERTAKE DISABLED":
-		p.eventState.OvertakeEnabled = Messages.OvertakeDisabled
-		p.eventState.Timestamp = time
-		*eventResult = append(*eventResult, p.eventState)
-
-		//default:
-		//	fmt.Println("Unhandled RC: " + status)
+	} else {
+		p.updateEventFromRaceControlText(status, time, eventResult)
 	}
 
 	if exists {
-		scope, _ := msg.(map[string]interface{})["Scope"].(string)
-		sectorNum := 0
-		if scope == "Sector" {
-			sectorNum = int(msg.(map[string]interface{})["Sector"].(float64))
-			sectorNum -= 1 // 0 indexing
-			// TODO - 2021 - Saudi Arabia Qualifying uses sector 0 so 1 isn't the first sector?
-		}
+		scope := rcm.Scope
+		validSector := scope == Messages.SectorScope && p.validMarshalSector(rcm.Sector, time)
 
 		switch flagTxt {
 		case "RED":
-			if scope == "Track" {
+			if scope == Messages.TrackScope {
 				p.eventState.TrackStatus = Messages.RedFlag
 			}
-			if scope == "Sector" {
-				p.eventState.SegmentFlags[sectorNum] = Messages.RedFlag
+			if validSector {
+				p.setSectorFlag(rcm.Sector, Messages.RedFlag, time, rcm.Lap)
 			}
 			p.eventState.Timestamp = time
 			*eventResult = append(*eventResult, p.eventState)
 
 		case "YELLOW":
-			if scope == "Track" {
+			if scope == Messages.TrackScope {
 				p.eventState.TrackStatus = Messages.YellowFlag
 			}
-			if scope == "Sector" && sectorNum >= 0 {
-				p.eventState.SegmentFlags[sectorNum] = Messages.YellowFlag
+			if validSector {
+				p.setSectorFlag(rcm.Sector, Messages.YellowFlag, time, rcm.Lap)
 			}
 			p.eventState.Timestamp = time
 			*eventResult = append(*eventResult, p.eventState)
 
 		case "DOUBLE YELLOW":
-			if scope == "Track" {
+			if scope == Messages.TrackScope {
 				p.eventState.TrackStatus = Messages.DoubleYellowFlag
 			}
-			if scope == "Sector" && sectorNum >= 0 {
-				p.eventState.SegmentFlags[sectorNum] = Messages.DoubleYellowFlag
+			if validSector {
+				p.setSectorFlag(rcm.Sector, Messages.DoubleYellowFlag, time, rcm.Lap)
 			}
 			p.eventState.Timestamp = time
 			*eventResult = append(*eventResult, p.eventState)
 
 		case "GREEN", "CLEAR":
-			if scope == "Track" {
+			if scope == Messages.TrackScope {
 				p.eventState.TrackStatus = Messages.GreenFlag
 				p.eventState.SafetyCar = Messages.Clear
 			}
-			if scope == "Sector" {
-				if sectorNum >= 0 {
-					p.eventState.SegmentFlags[sectorNum] = Messages.GreenFlag
-				}
-			} else {
-				for x := range p.eventState.SegmentFlags {
-					p.eventState.SegmentFlags[x] = Messages.GreenFlag
+			if scope == Messages.SectorScope {
+				if validSector {
+					p.setSectorFlag(rcm.Sector, Messages.GreenFlag, time, rcm.Lap)
 				}
+			} else if scope != Messages.DriverScope {
+				p.resetSectorFlags(Messages.GreenFlag, time, false)
 			}
 			p.eventState.Timestamp = time
 			*eventResult = append(*eventResult, p.eventState)
@@ -267,6 +198,165 @@ func (p *Parser) readRaceControlMessage(
 		}
 	}
 
-	// TODO - DRS enabled/disabled
-	// TODO - yellow flags per sector
+	p.updateLapTrackStatus()
+}
+
+func (p *Parser) readRaceControlFields(
+	record map[string]interface{},
+	category string,
+	timestamp time.Time) Messages.RaceControlMessage {
+
+	rcm := Messages.RaceControlMessage{
+		Timestamp: timestamp,
+	}
+
+	switch category {
+	case "Flag":
+		rcm.Category = Messages.FlagCategory
+	case "SafetyCar":
+		rcm.Category = Messages.SafetyCarCategory
+	case "Drs":
+		rcm.Category = Messages.DrsCategory
+	case "CarEvent":
+		rcm.Category = Messages.CarEventCategory
+	default:
+		rcm.Category = Messages.OtherCategory
+	}
+
+	scope, _ := record["Scope"].(string)
+	switch scope {
+	case "Track":
+		rcm.Scope = Messages.TrackScope
+	case "Sector":
+		rcm.Scope = Messages.SectorScope
+	case "Driver":
+		rcm.Scope = Messages.DriverScope
+	}
+
+	rcm.Sector = intValue(record["Sector"])
+	rcm.RacingNumber = intValue(record["RacingNumber"])
+	rcm.Lap = intValue(record["Lap"])
+
+	status, _ := record["Status"].(string)
+	switch status {
+	case "ENABLED":
+		rcm.Status = Messages.EnabledStatus
+	case "DISABLED":
+		rcm.Status = Messages.DisabledStatus
+	case "DEPLOYED":
+		rcm.Status = Messages.DeployedStatus
+	case "ENDING":
+		rcm.Status = Messages.EndingStatus
+	case "IN THIS LAP":
+		rcm.Status = Messages.InThisLapStatus
+	case "":
+	default:
+		p.ParseErrorf(connection.RaceControlMessagesFile, timestamp, "Unhandled race control status '%s'", status)
+	}
+
+	mode, _ := record["Mode"].(string)
+	switch mode {
+	case "SAFETY CAR":
+		rcm.Mode = Messages.SafetyCarMode
+	case "VIRTUAL SAFETY CAR":
+		rcm.Mode = Messages.VirtualSafetyCarMode
+	case "":
+	default:
+		p.ParseErrorf(connection.RaceControlMessagesFile, timestamp, "Unhandled race control mode '%s'", mode)
+	}
+
+	return rcm
+}
+
+// Update the event state from the DRS and safety car fields, returns false if the message doesn't have them
+func (p *Parser) updateEventFromRaceControl(rcm Messages.RaceControlMessage) bool {
+	switch rcm.Category {
+	case Messages.DrsCategory:
+		switch rcm.Status {
+		case Messages.EnabledStatus:
+			p.eventState.DRSEnabled = Messages.DRSEnabled
+		case Messages.DisabledStatus:
+			p.eventState.DRSEnabled = Messages.DRSDisabled
+		default:
+			return false
+		}
+
+	case Messages.SafetyCarCategory:
+		switch {
+		case rcm.Mode == Messages.VirtualSafetyCarMode && rcm.Status == Messages.DeployedStatus:
+			p.eventState.SafetyCar = Messages.VirtualSafetyCar
+			p.eventState.DRSEnabled = Messages.DRSDisabled
+		case rcm.Mode == Messages.VirtualSafetyCarMode && rcm.Status == Messages.EndingStatus:
+			p.eventState.SafetyCar = Messages.VirtualSafetyCarEnding
+		case rcm.Mode == Messages.SafetyCarMode && rcm.Status == Messages.DeployedStatus:
+			p.eventState.SafetyCar = Messages.SafetyCar
+			p.eventState.DRSEnabled = Messages.DRSDisabled
+		case rcm.Mode == Messages.SafetyCarMode && (rcm.Status == Messages.InThisLapStatus || rcm.Status == Messages.EndingStatus):
+			p.eventState.SafetyCar = Messages.SafetyCarEnding
+		default:
+			return false
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (p *Parser) updateEventFromRaceControlText(status string, time time.Time, eventResult *[]Messages.Event) {
+	switch status {
+	case "GREEN LIGHT - PIT EXIT OPEN":
+		p.eventState.PitExitOpen = true
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "RED LIGHT - PIT EXIT CLOSED":
+		p.eventState.PitExitOpen = false
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "VIRTUAL SAFETY CAR DEPLOYED":
+		p.eventState.SafetyCar = Messages.VirtualSafetyCar
+		p.eventState.Timestamp = time
+		p.eventState.DRSEnabled = Messages.DRSDisabled
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "VIRTUAL SAFETY CAR ENDING":
+		p.eventState.SafetyCar = Messages.VirtualSafetyCarEnding
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "SAFETY CAR DEPLOYED":
+		p.eventState.SafetyCar = Messages.SafetyCar
+		p.eventState.Timestamp = time
+		p.eventState.DRSEnabled = Messages.DRSDisabled
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "SAFETY CAR IN THIS LAP":
+		p.eventState.SafetyCar = Messages.SafetyCarEnding
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "DRS ENABLED":
+		p.eventState.DRSEnabled = Messages.DRSEnabled
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "DRS DISABLED":
+		p.eventState.DRSEnabled = Messages.DRSDisabled
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "OVERTAKE ENABLED":
+		p.eventState.OvertakeEnabled = Messages.OvertakeEnabled
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+
+	case "OVERTAKE DISABLED":
+		p.eventState.OvertakeEnabled = Messages.OvertakeDisabled
+		p.eventState.Timestamp = time
+		*eventResult = append(*eventResult, p.eventState)
+	}
+
 }
@@ -0,0 +1,222 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"sort"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+const DefaultResampleInterval = 100 * time.Millisecond
+
+// Don't interpolate across gaps in the data bigger than this, the car is probably in the garage
+const resampleMaxGap = 5 * time.Second
+
+// Samples received for a driver that haven't been used for all the resampled times yet
+type resampleBuffer struct {
+	telemetry []Messages.Telemetry
+	locations []Messages.Location
+	next      time.Time
+}
+
+func (p *Parser) SetResampleInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultResampleInterval
+	}
+
+	p.resampleLock.Lock()
+	defer p.resampleLock.Unlock()
+	p.resampleInterval = interval
+}
+
+func (p *Parser) resampleAddTelemetry(telemetry Messages.Telemetry) {
+	buffer := p.resampleBufferFor(telemetry.DriverNumber)
+
+	// Samples need to be in time order
+	if len(buffer.telemetry) > 0 && !telemetry.Timestamp.After(buffer.telemetry[len(buffer.telemetry)-1].Timestamp) {
+		return
+	}
+
+	telemetry.Timestamp = telemetry.Timestamp.UTC()
+	buffer.telemetry = append(buffer.telemetry, telemetry)
+}
+
+func (p *Parser) resampleAddLocation(location Messages.Location) {
+	buffer := p.resampleBufferFor(location.DriverNumber)
+
+	// Can't interpolate to or from a car with no location so start again when it comes back
+	if location.Status == Messages.PositionNoFix {
+		buffer.locations = nil
+		buffer.next = time.Time{}
+		return
+	}
+
+	if len(buffer.locations) > 0 && !location.Timestamp.After(buffer.locations[len(buffer.locations)-1].Timestamp) {
+		return
+	}
+
+	location.Timestamp = location.Timestamp.UTC()
+	buffer.locations = append(buffer.locations, location)
+}
+
+func (p *Parser) resampleBufferFor(driverNumber int) *resampleBuffer {
+	buffer, exists := p.resampleBuffers[driverNumber]
+	if !exists {
+		buffer = &resampleBuffer{}
+		p.resampleBuffers[driverNumber] = buffer
+	}
+	return buffer
+}
+
+// Create samples for every driver up to the latest time we have both telemetry and a location for them
+func (p *Parser) resample() []Messages.CarSample {
+	p.resampleLock.Lock()
+	interval := p.resampleInterval
+	p.resampleLock.Unlock()
+
+	result := make([]Messages.CarSample, 0)
+	for driverNumber, buffer := range p.resampleBuffers {
+		result = append(result, buffer.resample(driverNumber, interval)...)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		}
+		return result[i].DriverNumber < result[j].DriverNumber
+	})
+
+	return result
+}
+
+func (b *resampleBuffer) resample(driverNumber int, interval time.Duration) []Messages.CarSample {
+	var result []Messages.CarSample
+
+	b.trim()
+	if len(b.telemetry) == 0 || len(b.locations) == 0 {
+		return result
+	}
+
+	// Line the samples up on multiples of the interval so all drivers have the same times
+	if b.next.IsZero() {
+		start := b.telemetry[0].Timestamp
+		if b.locations[0].Timestamp.After(start) {
+			start = b.locations[0].Timestamp
+		}
+
+		b.next = start.Truncate(interval)
+		if b.next.Before(start) {
+			b.next = b.next.Add(interval)
+		}
+	}
+
+	end := b.telemetry[len(b.telemetry)-1].Timestamp
+	if b.locations[len(b.locations)-1].Timestamp.Before(end) {
+		end = b.locations[len(b.locations)-1].Timestamp
+	}
+
+	for ; !b.next.After(end); b.next = b.next.Add(interval) {
+		// Only keep the last sample at or before the next time and everything after it
+		for len(b.telemetry) > 1 && !b.telemetry[1].Timestamp.After(b.next) {
+			b.telemetry = b.telemetry[1:]
+		}
+		for len(b.locations) > 1 && !b.locations[1].Timestamp.After(b.next) {
+			b.locations = b.locations[1:]
+		}
+
+		before := b.telemetry[0]
+		after := before
+		if len(b.telemetry) > 1 {
+			after = b.telemetry[1]
+		}
+
+		beforeLocation := b.locations[0]
+		afterLocation := beforeLocation
+		if len(b.locations) > 1 {
+			afterLocation = b.locations[1]
+		}
+
+		telemetryFraction, telemetryOk := resampleFraction(before.Timestamp, after.Timestamp, b.next)
+		locationFraction, locationOk := resampleFraction(beforeLocation.Timestamp, afterLocation.Timestamp, b.next)
+		if !telemetryOk || !locationOk {
+			continue
+		}
+
+		// Values that can't be in between two samples use the earlier sample
+		result = append(result, Messages.CarSample{
+			Timestamp:    b.next,
+			DriverNumber: driverNumber,
+			X:            interpolate(beforeLocation.X, afterLocation.X, locationFraction),
+			Y:            interpolate(beforeLocation.Y, afterLocation.Y, locationFraction),
+			Z:            interpolate(beforeLocation.Z, afterLocation.Z, locationFraction),
+			RPM:          int16(interpolate(float64(before.RPM), float64(after.RPM), telemetryFraction)),
+			Speed:        float32(interpolate(float64(before.Speed), float64(after.Speed), telemetryFraction)),
+			Gear:         before.Gear,
+			Throttle:     float32(interpolate(float64(before.Throttle), float64(after.Throttle), telemetryFraction)),
+			Brake:        float32(interpolate(float64(before.Brake), float64(after.Brake), telemetryFraction)),
+			DRS:          before.DRS,
+			DRSState:     before.DRSState,
+		})
+	}
+
+	return result
+}
+
+// Drop samples that can never be used because the other data has stopped so the buffers don't grow
+// for the whole session. Only the latest sample is needed to start again when the other data returns.
+func (b *resampleBuffer) trim() {
+	// A feed that is further behind than we would interpolate across has stopped, the samples
+	// it would be interpolated with can't be used anyway
+	if len(b.telemetry) > 0 && len(b.locations) > 0 {
+		lastTelemetry := b.telemetry[len(b.telemetry)-1].Timestamp
+		lastLocation := b.locations[len(b.locations)-1].Timestamp
+
+		if lastLocation.Sub(lastTelemetry) > resampleMaxGap {
+			b.telemetry = nil
+			b.next = time.Time{}
+		} else if lastTelemetry.Sub(lastLocation) > resampleMaxGap {
+			b.locations = nil
+			b.next = time.Time{}
+		}
+	}
+
+	if len(b.telemetry) == 0 && len(b.locations) > 1 {
+		b.locations = b.locations[len(b.locations)-1:]
+	}
+	if len(b.locations) == 0 && len(b.telemetry) > 1 {
+		b.telemetry = b.telemetry[len(b.telemetry)-1:]
+	}
+}
+
+// How far between the two samples the given time is
+func resampleFraction(before time.Time, after time.Time, at time.Time) (float64, bool) {
+	if at.Equal(before) {
+		return 0, true
+	}
+
+	gap := after.Sub(before)
+	if gap <= 0 || gap > resampleMaxGap {
+		return 0, false
+	}
+
+	return float64(at.Sub(before)) / float64(gap), true
+}
+
+func interpolate(before float64, after float64, fraction float64) float64 {
+	return before + (after-before)*fraction
+}
@@ -0,0 +1,262 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+)
+
+// Marshal sectors and segments that only touch at their ends don't overlap
+const minSectorOverlap = 1.0 // Metres
+
+// Race control doesn't always clear sector flags so drop any that haven't been updated for this long
+const sectorFlagTimeout = 10 * time.Minute
+
+type sectorFlagState struct {
+	// Timing segments covered by each marshal sector when the track map has the marshal sectors
+	segments map[int][]int
+	// Some sessions number the sectors from 0 instead of 1 (2021 Saudi Arabia Qualifying)
+	zeroBased bool
+	updated   [Messages.MaxMarshalSectors]time.Time
+	history   map[int][]Messages.SectorFlag
+}
+
+func (p *Parser) SectorFlagHistory(sector int) []Messages.SectorFlag {
+	p.sectorFlagsLock.Lock()
+	defer p.sectorFlagsLock.Unlock()
+
+	history := p.sectorFlags.history[sector]
+	result := make([]Messages.SectorFlag, len(history))
+	copy(result, history)
+	return result
+}
+
+// Race control uses marshal sectors which don't line up with the timing segments so use where both are on the
+// track map to work out which segments each marshal sector covers
+func marshalSectorSegments(existing trackMap.TrackMap) map[int][]int {
+	result := make(map[int][]int)
+
+	lapDistance := trackMap.CreateLapDistance(existing.Outline)
+	if lapDistance == nil || len(existing.MarshalSectors) == 0 || len(existing.Segments) == 0 {
+		return result
+	}
+
+	segments := lapSpans(lapDistance, existing.Segments)
+	for sector, sectorSpan := range lapSpans(lapDistance, existing.MarshalSectors) {
+		for segment, segmentSpan := range segments {
+			// Segments are numbered from 1
+			if segment > 0 && segment <= Messages.MaxSegments &&
+				sectorSpan.overlap(segmentSpan, lapDistance.Length()) > minSectorOverlap {
+				result[sector] = append(result[sector], segment-1)
+			}
+		}
+		sort.Ints(result[sector])
+	}
+
+	return result
+}
+
+// Part of the lap in metres, wraps around the start/finish line when the end is before the start
+type lapSpan struct {
+	start float64
+	end   float64
+}
+
+// Boundaries are where something ends so it starts where the one numbered before it ends
+func lapSpans(lapDistance *trackMap.LapDistance, boundaries []trackMap.Boundary) map[int]lapSpan {
+	sorted := make([]trackMap.Boundary, len(boundaries))
+	copy(sorted, boundaries)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	result := make(map[int]lapSpan)
+	for x, boundary := range sorted {
+		previous := sorted[(x+len(sorted)-1)%len(sorted)]
+		start, _ := lapDistance.Distance(previous.Point)
+		end, _ := lapDistance.Distance(boundary.Point)
+		result[boundary.Number] = lapSpan{start: start, end: end}
+	}
+
+	return result
+}
+
+// How many metres the two parts of the lap have in common
+func (l lapSpan) overlap(other lapSpan, length float64) float64 {
+	parts := func(span lapSpan) []lapSpan {
+		if span.end >= span.start {
+			return []lapSpan{span}
+		}
+		return []lapSpan{{start: span.start, end: length}, {start: 0, end: span.end}}
+	}
+
+	result := 0.0
+	for _, a := range parts(l) {
+		for _, b := range parts(other) {
+			result += max(0, min(a.end, b.end)-max(a.start, b.start))
+		}
+	}
+	return result
+}
+
+// Race control numbers the sectors from 1 for most sessions but some use 0 (2021 Saudi Arabia Qualifying) so keep
+// the number as it is and allow for it when working out the segments
+func (p *Parser) validMarshalSector(sector int, timestamp time.Time) bool {
+	if sector < 0 || sector >= Messages.MaxMarshalSectors {
+		p.ParseErrorf(connection.RaceControlMessagesFile, timestamp, "Sector %d is outside of the %d marshal sectors", sector, Messages.MaxMarshalSectors)
+		return false
+	}
+
+	if sector == 0 {
+		p.sectorFlags.zeroBased = true
+	}
+
+	return true
+}
+
+// Timing segments covered by a marshal sector. Without the marshal sectors from the track map each one is taken to
+// be the segment with the same number.
+func (p *Parser) sectorSegments(sector int) []int {
+	if len(p.sectorFlags.segments) > 0 {
+		// Maps are numbered from 1 so move zero based sectors along by one
+		if _, exists := p.sectorFlags.segments[0]; !exists && p.sectorFlags.zeroBased {
+			sector++
+		}
+		return p.sectorFlags.segments[sector]
+	}
+
+	segment := sector
+	if !p.sectorFlags.zeroBased {
+		segment--
+	}
+
+	segmentCount := p.eventState.TotalSegments
+	if segmentCount == 0 || segmentCount > Messages.MaxSegments {
+		segmentCount = Messages.MaxSegments
+	}
+
+	if segment < 0 || segment >= segmentCount {
+		return nil
+	}
+
+	return []int{segment}
+}
+
+func (p *Parser) setSectorFlag(sector int, flag Messages.FlagState, timestamp time.Time, lap int) {
+	p.sectorFlags.updated[sector] = timestamp
+	p.eventState.MarshalSectorFlags[sector] = flag
+
+	segments := p.sectorSegments(sector)
+	p.updateSegmentFlags(segments)
+
+	p.addSectorFlagHistory(Messages.SectorFlag{
+		Timestamp: timestamp,
+		Sector:    sector,
+		Segments:  segments,
+		Flag:      flag,
+		Lap:       lap,
+	})
+}
+
+// A segment can be covered by more than one marshal sector so show the worst of their flags
+func (p *Parser) updateSegmentFlags(segments []int) {
+	for _, segment := range segments {
+		flag := Messages.NoFlag
+		for sector, sectorFlag := range p.eventState.MarshalSectorFlags {
+			if slices.Contains(p.sectorSegments(sector), segment) {
+				flag = worstFlag(flag, sectorFlag)
+			}
+		}
+		p.eventState.SegmentFlags[segment] = flag
+	}
+}
+
+// Set every sector and segment to the same flag, used when the whole track is cleared or the session changes
+func (p *Parser) resetSectorFlags(flag Messages.FlagState, timestamp time.Time, expired bool) {
+	for x := range p.eventState.SegmentFlags {
+		p.eventState.SegmentFlags[x] = flag
+	}
+
+	for x := range p.eventState.MarshalSectorFlags {
+		if p.eventState.MarshalSectorFlags[x] == flag {
+			continue
+		}
+		p.sectorFlags.updated[x] = timestamp
+		p.eventState.MarshalSectorFlags[x] = flag
+
+		// We don't know how many sectors the track has so only record the change for sectors we have seen
+		_, seen := p.sectorFlags.history[x]
+		_, mapped := p.sectorFlags.segments[x]
+		if !seen && !mapped {
+			continue
+		}
+
+		p.addSectorFlagHistory(Messages.SectorFlag{
+			Timestamp: timestamp,
+			Sector:    x,
+			Segments:  p.sectorSegments(x),
+			Flag:      flag,
+			Lap:       p.eventState.CurrentLap,
+			Expired:   expired,
+		})
+	}
+}
+
+// Remove any yellow or red sector flags that haven't been updated recently, returns true if any were removed
+func (p *Parser) expireSectorFlags(timestamp time.Time) bool {
+	expired := false
+
+	for x, flag := range p.eventState.MarshalSectorFlags {
+		if flag == Messages.NoFlag || flag == Messages.GreenFlag {
+			continue
+		}
+
+		if timestamp.Sub(p.sectorFlags.updated[x]) < sectorFlagTimeout {
+			continue
+		}
+
+		p.sectorFlags.updated[x] = timestamp
+		p.eventState.MarshalSectorFlags[x] = Messages.NoFlag
+		expired = true
+
+		segments := p.sectorSegments(x)
+		p.updateSegmentFlags(segments)
+
+		p.addSectorFlagHistory(Messages.SectorFlag{
+			Timestamp: timestamp,
+			Sector:    x,
+			Segments:  segments,
+			Flag:      Messages.NoFlag,
+			Lap:       p.eventState.CurrentLap,
+			Expired:   true,
+		})
+	}
+
+	return expired
+}
+
+func (p *Parser) addSectorFlagHistory(flag Messages.SectorFlag) {
+	p.sectorFlagsLock.Lock()
+	defer p.sectorFlagsLock.Unlock()
+
+	p.sectorFlags.history[flag.Sector] = append(p.sectorFlags.history[flag.Sector], flag)
+}
@@ -18,6 +18,7 @@ package parser
 import (
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"github.com/f1gopher/f1gopherlib/connection"
+	"strconv"
 	"time"
 )
 
@@ -26,35 +27,28 @@ func (p *Parser) parseSessionInfoData(dat map[string]interface{}, timestamp time
 	info, _ := dat["Meeting"].(map[string]interface{})
 	timingResult := make([]Messages.Timing, 0)
 
-	p.eventState.Name = info["Name"].(string)
-	// Key
-	// OfficialName
-	// Location
-	// Country
-	// Circuit
-	// ArchiveStatus
+	// Updates can contain only part of the session info so keep what we have for anything missing
+	meetingName, exists := info["Name"].(string)
+	if exists {
+		p.eventState.Name = meetingName
+	}
 
 	p.eventState.Heartbeat = true
 	previousType := p.eventState.Type
 
-	switch dat["Name"].(string) {
-	case "Race":
-		p.eventState.Type = Messages.Race
-	case "Qualifying", "Sprint Qualifying", "Sprint Shootout":
-		p.eventState.Type = Messages.Qualifying1
-	case "Sprint":
-		p.eventState.Type = Messages.Sprint
-	case "Practice 1":
-		p.eventState.Type = Messages.Practice1
-	case "Practice 2":
-		p.eventState.Type = Messages.Practice2
-	case "Practice 3":
-		p.eventState.Type = Messages.Practice3
-	default:
-		p.ParseErrorf(connection.SessionInfoFile, timestamp, "Unknown type: %s", dat["Name"].(string))
+	sessionName, exists := dat["Name"].(string)
+	if exists {
+		p.updateSessionType(sessionName, timestamp)
 	}
 
 	if previousType != p.eventState.Type {
+		p.resetSectorFlags(Messages.NoFlag, timestamp, true)
+		p.sessionStarted = time.Time{}
+		p.resetQualifying()
+		p.resetLapHistory()
+		p.resetClassification()
+		p.resetPenalties()
+
 		// Clear the chequered flag state for all cars
 		for driverNum, driverInfo := range p.driverTimes {
 			driverInfo.ChequeredFlag = false
@@ -63,13 +57,16 @@ func (p *Parser) parseSessionInfoData(dat map[string]interface{}, timestamp time
 			driverInfo.Sector3 = 0
 			driverInfo.OverallFastestLap = false
 			driverInfo.FastestLap = 0
-			driverInfo.TimeDiffToPositionAhead = 0
-			driverInfo.TimeDiffToFastest = 0
-			driverInfo.GapToLeader = 0
+			driverInfo.TimeDiffToPositionAhead = Messages.Gap{}
+			driverInfo.TimeDiffToFastest = Messages.Gap{}
+			driverInfo.GapToLeader = Messages.Gap{}
 			driverInfo.LastLap = 0
 			driverInfo.SpeedTrap = 0
 			driverInfo.SpeedTrapOverallFastest = false
 			driverInfo.SpeedTrapPersonalFastest = false
+			for x := range driverInfo.Speeds {
+				driverInfo.Speeds[x] = Messages.TrapSpeed{}
+			}
 			driverInfo.Sector1OverallFastest = false
 			driverInfo.Sector1PersonalFastest = false
 			driverInfo.Sector2OverallFastest = false
@@ -80,6 +77,24 @@ func (p *Parser) parseSessionInfoData(dat map[string]interface{}, timestamp time
 				driverInfo.Segment[x] = Messages.None
 			}
 			driverInfo.Location = Messages.NoLocation
+			driverInfo.InPit = false
+			driverInfo.PitOut = false
+			driverInfo.Stopped = false
+			driverInfo.Retired = false
+			driverInfo.Status = 0
+			driverInfo.BlueFlag = false
+			driverInfo.BlueFlags = 0
+			driverInfo.BlackAndWhiteFlag = false
+			driverInfo.BlackAndWhiteFlags = 0
+			driverInfo.TrackLimitsWarnings = 0
+			driverInfo.TimePenaltySeconds = 0
+			driverInfo.Stints = nil
+			driverInfo.KnockedOutOfQualifying = false
+			driverInfo.KnockedOutInPart = 0
+			driverInfo.EliminationOrder = 0
+			for x := range driverInfo.QualifyingTimes {
+				driverInfo.QualifyingTimes[x] = 0
+			}
 
 			p.driverTimes[driverNum] = driverInfo
 
@@ -87,10 +102,162 @@ func (p *Parser) parseSessionInfoData(dat map[string]interface{}, timestamp time
 		}
 	}
 
-	// TODO handle: StartDate, GmtOffset, ArchiveStatus, Key, Name, EndDate, Path
-	// TODO handle: Meeting: Key, OfficialName, Location, Country, Circuit
+	p.updateQualifyingPart()
+
+	p.readSessionInfo(dat, info, timestamp)
 
 	p.eventState.Timestamp = timestamp
 
 	return p.eventState, timingResult, nil
 }
+
+func (p *Parser) updateSessionType(sessionName string, timestamp time.Time) {
+	switch sessionName {
+	case "Race":
+		p.eventState.Type = Messages.Race
+	case "Qualifying", "Sprint Qualifying", "Sprint Shootout":
+		// Session info can be sent again part way through qualifying so don't go back to the first part and lose
+		// the times we have
+		if p.qualifyingPart() == 0 {
+			p.eventState.Type = Messages.Qualifying1
+		}
+		p.eventState.SprintQualifying = sessionName != "Qualifying"
+	case "Sprint":
+		p.eventState.Type = Messages.Sprint
+	case "Practice 1":
+		p.eventState.Type = Messages.Practice1
+	case "Practice 2":
+		p.eventState.Type = Messages.Practice2
+	case "Practice 3":
+		p.eventState.Type = Messages.Practice3
+	default:
+		p.ParseErrorf(connection.SessionInfoFile, timestamp, "Unknown type: %s", sessionName)
+	}
+}
+
+func (p *Parser) SessionInfo() Messages.SessionInfo {
+	p.sessionInfoLock.Lock()
+	defer p.sessionInfoLock.Unlock()
+
+	return p.sessionInfo
+}
+
+func (p *Parser) readSessionInfo(dat map[string]interface{}, meeting map[string]interface{}, timestamp time.Time) {
+	var err error
+
+	// Updates only contain the values that have changed so start from what we already have
+	p.sessionInfoLock.Lock()
+	info := p.sessionInfo
+	p.sessionInfoLock.Unlock()
+	info.Timestamp = timestamp
+
+	readString := func(record map[string]interface{}, name string, value *string) {
+		if newValue, exists := record[name].(string); exists {
+			*value = newValue
+		}
+	}
+	readKey := func(record map[string]interface{}, value *int) {
+		if key, exists := record["Key"]; exists {
+			*value = intValue(key)
+		}
+	}
+
+	readKey(dat, &info.Key)
+	readString(dat, "Path", &info.Path)
+
+	name, exists := dat["Name"].(string)
+	if exists {
+		info.Name = name
+
+		switch info.Name {
+		case "Race":
+			info.Type = Messages.RaceSession
+		case "Qualifying", "Sprint Qualifying", "Sprint Shootout":
+			info.Type = Messages.QualifyingSession
+		case "Sprint":
+			info.Type = Messages.SprintSession
+		case "Practice 1":
+			info.Type = Messages.Practice1Session
+		case "Practice 2":
+			info.Type = Messages.Practice2Session
+		case "Practice 3":
+			info.Type = Messages.Practice3Session
+		default:
+			info.Type = Messages.PreSeasonSession
+		}
+	}
+
+	archive, exists := dat["ArchiveStatus"].(map[string]interface{})
+	if exists {
+		readString(archive, "Status", &info.ArchiveStatus)
+	}
+
+	offset, exists := dat["GmtOffset"].(string)
+	if exists {
+		info.GmtOffset, err = parseGmtOffset(offset)
+		if err != nil {
+			p.ParseTimeError(connection.SessionInfoFile, timestamp, "GmtOffset", err)
+		} else {
+			info.Timezone = time.FixedZone(info.Meeting.Location, int(info.GmtOffset.Seconds()))
+		}
+	}
+
+	// Dates are in the circuits local time
+	readDate := func(name string, value *time.Time) {
+		date, exists := dat[name].(string)
+		if !exists {
+			return
+		}
+
+		result, err := parseTime(date)
+		if err != nil {
+			p.ParseTimeError(connection.SessionInfoFile, timestamp, name, err)
+			return
+		}
+		*value = result.Add(-info.GmtOffset).UTC()
+	}
+	readDate("StartDate", &info.StartDate)
+	readDate("EndDate", &info.EndDate)
+
+	if meeting != nil {
+		readKey(meeting, &info.Meeting.Key)
+		readString(meeting, "Name", &info.Meeting.Name)
+		readString(meeting, "OfficialName", &info.Meeting.OfficialName)
+		readString(meeting, "Location", &info.Meeting.Location)
+
+		country, exists := meeting["Country"].(map[string]interface{})
+		if exists {
+			readKey(country, &info.Meeting.Country.Key)
+			readString(country, "Code", &info.Meeting.Country.Code)
+			readString(country, "Name", &info.Meeting.Country.Name)
+		}
+
+		circuit, exists := meeting["Circuit"].(map[string]interface{})
+		if exists {
+			readKey(circuit, &info.Meeting.Circuit.Key)
+			readString(circuit, "ShortName", &info.Meeting.Circuit.ShortName)
+		}
+	}
+
+	// Name the zone after the circuit once we know it
+	if info.Timezone != nil {
+		info.Timezone = time.FixedZone(info.Meeting.Location, int(info.GmtOffset.Seconds()))
+	}
+
+	p.sessionInfoLock.Lock()
+	p.sessionInfo = info
+	p.sessionInfoLock.Unlock()
+}
+
+// Keys can be sent as either a number or a string
+func intValue(value interface{}) int {
+	switch value.(type) {
+	case float64:
+		return int(value.(float64))
+	case string:
+		result, _ := strconv.Atoi(value.(string))
+		return result
+	default:
+		return 0
+	}
+}
@@ -21,9 +21,10 @@ import (
 	"time"
 )
 
-func (p *Parser) parseSessionStatusData(dat map[string]interface{}, timestamp time.Time) (Messages.Event, error) {
+func (p *Parser) parseSessionStatusData(dat map[string]interface{}, timestamp time.Time) (Messages.Event, []Messages.Classification, error) {
 
 	status := dat["Status"].(string)
+	previousStatus := p.eventState.Status
 
 	switch status {
 	case "Inactive":
@@ -44,5 +45,21 @@ func (p *Parser) parseSessionStatusData(dat map[string]interface{}, timestamp ti
 
 	p.eventState.Timestamp = timestamp
 
-	return p.eventState, nil
+	// Don't know when the session started from the catchup data
+	if p.eventState.Status == Messages.Started && p.sessionStarted.IsZero() && !p.catchup {
+		p.sessionStarted = timestamp
+	}
+
+	// Flags from before don't apply anymore
+	if previousStatus != p.eventState.Status {
+		p.resetSectorFlags(Messages.NoFlag, timestamp, true)
+	}
+
+	// Provisional classification when the session finishes and again once it is confirmed
+	classification := make([]Messages.Classification, 0)
+	if p.eventState.Status == Messages.Finished || p.eventState.Status == Messages.Finalised {
+		classification = append(classification, p.createClassification(timestamp))
+	}
+
+	return p.eventState, classification, nil
 }
@@ -0,0 +1,174 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/flowControl"
+)
+
+// Puts every message on the same UTC timeline relative to the session start before passing it on
+type sessionTimeFlow struct {
+	flowControl.Flow
+
+	p *Parser
+}
+
+func (f *sessionTimeFlow) AddWeather(weather Messages.Weather) {
+	weather.Timestamp, weather.SessionTime, weather.LeaderLap = f.p.sessionTime(weather.Timestamp)
+	f.Flow.AddWeather(weather)
+}
+
+func (f *sessionTimeFlow) AddRaceControlMessage(raceControl Messages.RaceControlMessage) {
+	raceControl.Timestamp, raceControl.SessionTime, raceControl.LeaderLap = f.p.sessionTime(raceControl.Timestamp)
+	f.Flow.AddRaceControlMessage(raceControl)
+}
+
+func (f *sessionTimeFlow) AddTiming(timing Messages.Timing) {
+	timing.Timestamp, timing.SessionTime, timing.LeaderLap = f.p.sessionTime(timing.Timestamp)
+	f.Flow.AddTiming(timing)
+}
+
+func (f *sessionTimeFlow) AddEvent(event Messages.Event) {
+	event.Timestamp, event.SessionTime, event.LeaderLap = f.p.sessionTime(event.Timestamp)
+	f.Flow.AddEvent(event)
+}
+
+func (f *sessionTimeFlow) AddTelemetry(telemetry Messages.Telemetry) {
+	telemetry.Timestamp, telemetry.SessionTime, telemetry.LeaderLap = f.p.sessionTime(telemetry.Timestamp)
+	f.Flow.AddTelemetry(telemetry)
+}
+
+func (f *sessionTimeFlow) AddLocation(location Messages.Location) {
+	location.Timestamp, location.SessionTime, location.LeaderLap = f.p.sessionTime(location.Timestamp)
+	f.Flow.AddLocation(location)
+}
+
+func (f *sessionTimeFlow) AddRadio(radio Messages.Radio) {
+	radio.Timestamp, radio.SessionTime, radio.LeaderLap = f.p.sessionTime(radio.Timestamp)
+	f.Flow.AddRadio(radio)
+}
+
+func (f *sessionTimeFlow) AddDrivers(drivers Messages.Drivers) {
+	drivers.Timestamp, drivers.SessionTime, drivers.LeaderLap = f.p.sessionTime(drivers.Timestamp)
+	f.Flow.AddDrivers(drivers)
+}
+
+func (f *sessionTimeFlow) AddLap(lap Messages.Lap) {
+	lap.Timestamp, lap.SessionTime, lap.LeaderLap = f.p.sessionTime(lap.Timestamp)
+	f.Flow.AddLap(lap)
+}
+
+func (f *sessionTimeFlow) AddClassification(classification Messages.Classification) {
+	classification.Timestamp, classification.SessionTime, classification.LeaderLap = f.p.sessionTime(classification.Timestamp)
+	f.Flow.AddClassification(classification)
+}
+
+func (f *sessionTimeFlow) AddPenalty(penalty Messages.Incident) {
+	penalty.Timestamp, penalty.SessionTime, penalty.LeaderLap = f.p.sessionTime(penalty.Timestamp)
+	f.Flow.AddPenalty(penalty)
+}
+
+func (f *sessionTimeFlow) AddLocationFrame(frame Messages.LocationFrame) {
+	frame.Timestamp, frame.SessionTime, frame.LeaderLap = f.p.sessionTime(frame.Timestamp)
+	for x := range frame.Locations {
+		frame.Locations[x].Timestamp = frame.Timestamp
+		frame.Locations[x].SessionTime = frame.SessionTime
+		frame.Locations[x].LeaderLap = frame.LeaderLap
+	}
+	f.Flow.AddLocationFrame(frame)
+}
+
+func (f *sessionTimeFlow) AddTelemetryFrame(frame Messages.TelemetryFrame) {
+	frame.Timestamp, frame.SessionTime, frame.LeaderLap = f.p.sessionTime(frame.Timestamp)
+	for x := range frame.Telemetry {
+		frame.Telemetry[x].Timestamp = frame.Timestamp
+		frame.Telemetry[x].SessionTime = frame.SessionTime
+		frame.Telemetry[x].LeaderLap = frame.LeaderLap
+	}
+	f.Flow.AddTelemetryFrame(frame)
+}
+
+func (f *sessionTimeFlow) AddCarSample(sample Messages.CarSample) {
+	sample.Timestamp, sample.SessionTime, sample.LeaderLap = f.p.sessionTime(sample.Timestamp)
+	f.Flow.AddCarSample(sample)
+}
+
+func (f *sessionTimeFlow) AddIntervals(intervals Messages.Intervals) {
+	intervals.Timestamp, intervals.SessionTime, intervals.LeaderLap = f.p.sessionTime(intervals.Timestamp)
+	f.Flow.AddIntervals(intervals)
+}
+
+func (f *sessionTimeFlow) AddLapCorners(corners Messages.LapCorners) {
+	corners.Timestamp, corners.SessionTime, corners.LeaderLap = f.p.sessionTime(corners.Timestamp)
+	f.Flow.AddLapCorners(corners)
+}
+
+func (p *Parser) sessionTime(timestamp time.Time) (time.Time, time.Duration, int) {
+	timestamp = timestamp.UTC()
+
+	// Use when the session actually started if we know it otherwise when it should have started. Session info is only
+	// changed by the parser so we don't need the lock to read it here.
+	start := p.sessionStarted
+	if start.IsZero() {
+		start = p.sessionInfo.StartDate
+	}
+
+	var sessionTime time.Duration
+	if !start.IsZero() && !timestamp.IsZero() {
+		sessionTime = timestamp.Sub(start)
+	}
+
+	return timestamp, sessionTime, p.leaderLap()
+}
+
+func (p *Parser) leaderLap() int {
+	if p.eventState.CurrentLap > 0 {
+		return p.eventState.CurrentLap
+	}
+
+	// Only races have a lap count so use the lap of the car in first place, which is one on from the laps it has
+	// completed
+	for _, driver := range p.driverTimes {
+		if driver.Position == 1 {
+			return driver.Lap + 1
+		}
+	}
+
+	return 0
+}
+
+// Catchup data doesn't have a timestamp so use the time of the heartbeat or the clock in it. If it has neither the
+// timestamp is left zero rather than using the time now so that replays give the same result every time.
+func catchupTimestamp(dat map[string]interface{}) time.Time {
+	for _, fileName := range []string{connection.HeartbeatFile, connection.ExtrapolatedClockFile} {
+		record, exists := dat[fileName].(map[string]interface{})
+		if !exists {
+			continue
+		}
+
+		utc, _ := record["Utc"].(string)
+		timestamp, err := parseTime(utc)
+		if err == nil {
+			return timestamp
+		}
+	}
+
+	return time.Time{}
+}
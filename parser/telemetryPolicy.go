@@ -0,0 +1,125 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+type TelemetryMode int
+
+const (
+	// Don't send samples when the engine is off (RPM is 0)
+	DropEngineOff TelemetryMode = iota
+	// Send every sample
+	PassAll
+	// Send samples when the engine is off but no more often than the idle interval
+	ThrottledIdle
+)
+
+func (t TelemetryMode) String() string {
+	return [...]string{"Drop Engine Off", "Pass All", "Throttled Idle"}[t]
+}
+
+type TelemetryPolicy struct {
+	Mode TelemetryMode
+
+	// How often to send a sample for a car with the engine off when using ThrottledIdle
+	IdleInterval time.Duration
+
+	// Only send every Nth sample, 0 or 1 sends every sample. Drivers not in Decimation use DefaultDecimation.
+	DefaultDecimation int
+	Decimation        map[int]int
+}
+
+func DefaultTelemetryPolicy() TelemetryPolicy {
+	return TelemetryPolicy{
+		Mode:         DropEngineOff,
+		IdleInterval: time.Second,
+	}
+}
+
+// Per driver info needed to apply the policy
+type telemetryPolicyState struct {
+	lastIdleSample time.Time
+	engineOff      bool
+	samples        int
+}
+
+func (p *Parser) SetTelemetryPolicy(policy TelemetryPolicy) {
+	decimation := make(map[int]int, len(policy.Decimation))
+	for driver, every := range policy.Decimation {
+		decimation[driver] = every
+	}
+	policy.Decimation = decimation
+
+	p.telemetryPolicyLock.Lock()
+	defer p.telemetryPolicyLock.Unlock()
+	p.telemetryPolicy = policy
+}
+
+func (p *Parser) currentTelemetryPolicy() TelemetryPolicy {
+	p.telemetryPolicyLock.Lock()
+	defer p.telemetryPolicyLock.Unlock()
+	return p.telemetryPolicy
+}
+
+// Should the sample be sent based on the engine state and decimation for the driver
+func (p *Parser) applyTelemetryPolicy(policy TelemetryPolicy, telemetry Messages.Telemetry) bool {
+	state, exists := p.telemetryPolicyStates[telemetry.DriverNumber]
+	if !exists {
+		state = &telemetryPolicyState{}
+		p.telemetryPolicyStates[telemetry.DriverNumber] = state
+	}
+
+	engineOff := telemetry.RPM == 0
+	engineStopped := engineOff && !state.engineOff
+	state.engineOff = engineOff
+
+	if engineOff {
+		switch policy.Mode {
+		case DropEngineOff:
+			return false
+
+		case ThrottledIdle:
+			// Always send the moment the engine stops so stalls and failures can be seen,
+			// whatever the decimation is
+			if engineStopped {
+				state.lastIdleSample = telemetry.Timestamp
+				return true
+			}
+
+			if telemetry.Timestamp.Sub(state.lastIdleSample) < policy.IdleInterval {
+				return false
+			}
+			state.lastIdleSample = telemetry.Timestamp
+		}
+	}
+
+	every, exists := policy.Decimation[telemetry.DriverNumber]
+	if !exists {
+		every = policy.DefaultDecimation
+	}
+
+	state.samples++
+	if every > 1 && state.samples%every != 1 {
+		return false
+	}
+
+	return true
+}
@@ -16,8 +16,12 @@
 package parser
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
 )
 
 func parseTime(timestamp string) (time.Time, error) {
@@ -40,3 +44,46 @@ func parseDuration(duration string) (time.Duration, error) {
 	previousValueStr = previousValueStr + "ms"
 	return time.ParseDuration(previousValueStr)
 }
+
+// Offsets are given as "01:00:00" or "-04:00:00"
+func parseGmtOffset(offset string) (time.Duration, error) {
+	negative := strings.HasPrefix(offset, "-")
+	offset = strings.TrimLeft(offset, "+-")
+
+	var hours, minutes, seconds int
+	_, err := fmt.Sscanf(offset, "%d:%d:%d", &hours, &minutes, &seconds)
+	if err != nil {
+		return 0, err
+	}
+
+	result := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if negative {
+		result = -result
+	}
+	return result, nil
+}
+
+// Gaps are either a time, a number of laps behind ("1L", "+2 LAPS") or for the leader the lap they are on ("LAP 45")
+func parseGap(gap string) (Messages.Gap, error) {
+	gap = strings.TrimSpace(gap)
+	if len(gap) == 0 {
+		return Messages.Gap{}, nil
+	}
+
+	if strings.HasPrefix(gap, "LAP") {
+		lap, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(gap, "LAP")))
+		return Messages.Gap{Type: Messages.LeaderLapGap, Laps: lap}, err
+	}
+
+	if strings.HasSuffix(gap, "L") || strings.HasSuffix(gap, "LAP") || strings.HasSuffix(gap, "LAPS") {
+		laps := strings.TrimPrefix(gap, "+")
+		laps = strings.TrimSuffix(laps, "LAPS")
+		laps = strings.TrimSuffix(laps, "LAP")
+		laps = strings.TrimSuffix(laps, "L")
+		count, err := strconv.Atoi(strings.TrimSpace(laps))
+		return Messages.Gap{Type: Messages.LapsGap, Laps: count}, err
+	}
+
+	t, err := parseDuration(gap)
+	return Messages.Gap{Type: Messages.TimeGap, Time: t}, err
+}
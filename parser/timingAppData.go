@@ -18,6 +18,7 @@ package parser
 import (
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"github.com/f1gopher/f1gopherlib/connection"
+	"strconv"
 	"time"
 )
 
@@ -25,19 +26,30 @@ func (p *Parser) parseTimingAppData(dat map[string]interface{}, timestamp time.T
 
 	result := make([]Messages.Timing, 0)
 
-	for driverStr, line := range dat["Lines"].(map[string]interface{}) {
+	lines, exists := dat["Lines"].(map[string]interface{})
+	if !exists {
+		return result, nil
+	}
+
+	for driverStr, line := range lines {
 
 		currentDriver, exists := p.driverTimes[driverStr]
 		if !exists {
 			continue
 		}
+
+		record, exists := line.(map[string]interface{})
+		if !exists {
+			p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled line format: %v", line)
+			continue
+		}
 		currentDriver.Timestamp = timestamp
 
 		// Don't use this because if you join a race part way through it overwrites
 		// the current positions with the start positions and things don't
 		// correct until there is a pit stop.
 		//
-		//value, exists := line.(map[string]interface{})["GridPos"]
+		//value, exists := record["GridPos"]
 		//if exists {
 		//	value, _ := strconv.ParseInt(value.(string), 10, 8)
 		//	currentDriver.Position = int(value)
@@ -46,28 +58,51 @@ func (p *Parser) parseTimingAppData(dat map[string]interface{}, timestamp time.T
 		// Don't use this to update the driver position because it results in multiple drivers
 		// with the same position.
 		//
-		//value, exists = line.(map[string]interface{})["Line"]
+		//value, exists = record["Line"]
 		//if exists {
 		//	currentDriver.Position = int(value.(float64))
 		//}
 
-		value, exists := line.(map[string]interface{})["Stints"]
+		value, exists := record["Stints"]
 		if exists {
+			// Copy so we don't change the stints in messages that have already been sent
+			stints := make([]Messages.Stint, len(currentDriver.Stints))
+			copy(stints, currentDriver.Stints)
+			updates := make(map[int]stintUpdate)
 
 			switch value.(type) {
 			case map[string]interface{}:
-				for _, stintData := range value.(map[string]interface{}) {
-					p.readTimingAppData(stintData, &currentDriver, timestamp)
+				for key, stintData := range value.(map[string]interface{}) {
+					index, err := strconv.Atoi(key)
+					if err != nil {
+						p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled stint index '%s'", key)
+						continue
+					}
+					stints, updates[index] = p.readTimingAppData(index, stintData, stints, timestamp)
 				}
 
 			case []interface{}:
-				for _, stintData := range value.([]interface{}) {
-					p.readTimingAppData(stintData, &currentDriver, timestamp)
+				for index, stintData := range value.([]interface{}) {
+					stints, updates[index] = p.readTimingAppData(index, stintData, stints, timestamp)
 				}
 
 			default:
 				p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled data format: %v", dat)
 			}
+
+			currentDriver.Stints = stints
+			p.updateStints(&currentDriver)
+
+			// A new stint doesn't always have the compound straight away so keep the previous tire until it does
+			if len(stints) > 0 {
+				last := len(stints) - 1
+				if updates[last].compound {
+					currentDriver.Tire = stints[last].Compound
+				}
+				if updates[last].totalLaps {
+					currentDriver.LapsOnTire = stints[last].TotalLaps
+				}
+			}
 		}
 
 		p.driverTimes[driverStr] = currentDriver
@@ -78,45 +113,130 @@ func (p *Parser) parseTimingAppData(dat map[string]interface{}, timestamp time.T
 	return result, nil
 }
 
-func (p *Parser) readTimingAppData(stintData interface{}, currentDriver *Messages.Timing, timestamp time.Time) {
-	tyre, hasTyre := stintData.(map[string]interface{})["Compound"]
-	if !hasTyre {
-		return
+// Which values an update contained for a stint
+type stintUpdate struct {
+	compound  bool
+	totalLaps bool
+}
+
+func (p *Parser) readTimingAppData(
+	index int,
+	stintData interface{},
+	stints []Messages.Stint,
+	timestamp time.Time) ([]Messages.Stint, stintUpdate) {
+
+	update := stintUpdate{}
+
+	data, exists := stintData.(map[string]interface{})
+	if !exists {
+		p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled stint format: %v", stintData)
+		return stints, update
+	}
+
+	for len(stints) <= index {
+		stints = append(stints, Messages.Stint{})
+	}
+	stint := &stints[index]
+
+	tyre, hasTyre := data["Compound"].(string)
+	if hasTyre {
+		update.compound = true
+
+		switch tyre {
+		case "SOFT":
+			stint.Compound = Messages.Soft
+		case "MEDIUM":
+			stint.Compound = Messages.Medium
+		case "HARD":
+			stint.Compound = Messages.Hard
+		case "INTERMEDIATE":
+			stint.Compound = Messages.Intermediate
+		case "WET":
+			stint.Compound = Messages.Wet
+		case "UNKNOWN", "C": // Apparently a thing!
+			stint.Compound = Messages.Unknown
+		case "TEST", "TEST_UNKNOWN":
+			stint.Compound = Messages.Test
+		case "HYPERSOFT":
+			stint.Compound = Messages.HYPERSOFT
+		case "SUPERSOFT":
+			stint.Compound = Messages.SUPERSOFT
+		case "ULTRASOFT":
+			stint.Compound = Messages.ULTRASOFT
+		default:
+			p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled Compound '%s'", tyre)
+			update.compound = false
+		}
 	}
 
-	switch tyre.(string) {
-	case "SOFT":
-		currentDriver.Tire = Messages.Soft
-	case "MEDIUM":
-		currentDriver.Tire = Messages.Medium
-	case "HARD":
-		currentDriver.Tire = Messages.Hard
-	case "INTERMEDIATE":
-		currentDriver.Tire = Messages.Intermediate
-	case "WET":
-		currentDriver.Tire = Messages.Wet
-	case "UNKNOWN", "C": // Apparently a thing!
-		currentDriver.Tire = Messages.Unknown
-	case "TEST", "TEST_UNKNOWN":
-		currentDriver.Tire = Messages.Test
-	case "HYPERSOFT":
-		currentDriver.Tire = Messages.HYPERSOFT
-	case "SUPERSOFT":
-		currentDriver.Tire = Messages.SUPERSOFT
-	case "ULTRASOFT":
-		currentDriver.Tire = Messages.ULTRASOFT
-	default:
-		p.ParseErrorf(connection.TimingAppDataFile, timestamp, "Unhandled Compound '%s'", tyre.(string))
+	// Sent as either a string or a bool depending on the year
+	switch value := data["New"].(type) {
+	case string:
+		stint.New = value == "true"
+	case bool:
+		stint.New = value
 	}
 
-	//drivers[driverNumber].PitStops = append(drivers[driverNumber].PitStops, driver.PitStop{
-	//	Lap: drivers[driverNumber].Lap,
-	//})
+	switch value := data["TyresNotChanged"].(type) {
+	case string:
+		stint.TyresNotChanged = value == "1"
+	case float64:
+		stint.TyresNotChanged = value == 1
+	}
 
-	// TODO - Handle: LapFlags, New, TyresNotChanged, TotalLaps, StartLaps
+	startLaps, exists := data["StartLaps"].(float64)
+	if exists {
+		stint.StartLaps = int(startLaps)
+	}
+
+	totalLaps, exists := data["TotalLaps"].(float64)
+	if exists {
+		stint.TotalLaps = int(totalLaps)
+		update.totalLaps = true
+	}
 
-	totalLaps, exists := stintData.(map[string]interface{})["TotalLaps"]
+	lapFlags, exists := data["LapFlags"].(float64)
 	if exists {
-		currentDriver.LapsOnTire = int(totalLaps.(float64))
+		stint.LapFlags = int(lapFlags)
+	}
+
+	return stints, update
+}
+
+// Work out which laps of the session each stint covers from the number of laps done on the tires and fill in
+// the lap times for those laps
+func (p *Parser) updateStints(driver *Messages.Timing) {
+	if len(driver.Stints) == 0 {
+		return
+	}
+
+	// Copy so we don't change the stints in messages that have already been sent
+	stints := make([]Messages.Stint, len(driver.Stints))
+	copy(stints, driver.Stints)
+	driver.Stints = stints
+
+	p.lapHistoryLock.Lock()
+	laps := p.lapHistory[strconv.Itoa(driver.Number)]
+
+	startLap := 1
+	for x := range stints {
+		stint := &stints[x]
+		stintLaps := stint.TotalLaps - stint.StartLaps
+
+		stint.StartLap = startLap
+		stint.EndLap = 0
+		if stintLaps > 0 {
+			stint.EndLap = startLap + stintLaps - 1
+		}
+		startLap += stintLaps
+
+		stint.LapTimes = nil
+		for _, lap := range laps {
+			// The current stint gets all laps since it started even if the stint laps haven't caught up yet
+			if lap.Lap >= stint.StartLap && (lap.Lap <= stint.EndLap || x == len(stints)-1) {
+				stint.LapTimes = append(stint.LapTimes, lap.LapTime)
+			}
+		}
 	}
+	p.lapHistoryLock.Unlock()
 }
@@ -21,17 +21,19 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 )
 
-func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time) ([]Messages.Timing, error) {
+func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time) ([]Messages.Timing, []Messages.Lap, error) {
 
 	result := make([]Messages.Timing, 0)
+	lapResult := make([]Messages.Lap, 0)
+
+	p.parseQualifyingSessionData(dat, timestamp)
 
 	lines, exists := dat["Lines"]
 	if !exists {
-		return result, nil
+		return result, lapResult, nil
 	}
 
 	fastestLapChanged := false
@@ -47,14 +49,16 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 		}
 
 		currentDriver.Timestamp = timestamp
+		previousLap := currentDriver.Lap
 
-		value, exists := record["Stopped"]
+		hasCarState := p.readCarState(record, &currentDriver)
 
 		intValue, exists := record["NumberOfPitStops"]
 		if exists {
 			currentDriver.Pitstops = int(intValue.(float64))
 		}
 
+		var value interface{}
 		value, exists = record["Position"].(string)
 		if exists {
 			pos, _ := strconv.Atoi(value.(string))
@@ -64,51 +68,27 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 		// TODO - do we ever get both values at the same time? Should we just use the value we get as the gap?
 		value, exists = record["TimeDiffToFastest"].(string)
 		if exists {
-			var t time.Duration
-
-			if len(value.(string)) > 0 {
-				t, err = parseDuration(value.(string))
-				if err != nil {
-					p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToFastest", err)
-				}
+			currentDriver.TimeDiffToFastest, err = parseGap(value.(string))
+			if err != nil {
+				p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToFastest", err)
 			}
-
-			currentDriver.TimeDiffToFastest = t
 		}
 
 		value, exists = record["TimeDiffToPositionAhead"].(string)
 		if exists {
-			var t time.Duration
-
-			if len(value.(string)) > 0 {
-				t, err = parseDuration(value.(string))
-				if err != nil {
-					p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToPositionAhead", err)
-				}
+			currentDriver.TimeDiffToPositionAhead, err = parseGap(value.(string))
+			if err != nil {
+				p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToPositionAhead", err)
 			}
-
-			currentDriver.TimeDiffToPositionAhead = t
 		}
 
 		value, exists = record["GapToLeader"].(string)
 		if exists {
-			var t time.Duration
-
-			if len(value.(string)) > 0 &&
-				!strings.HasPrefix(value.(string), "LAP") &&
-				!strings.HasSuffix(value.(string), "L") {
-
-				t, err = parseDuration(value.(string))
-				if err != nil {
-					p.ParseTimeError(connection.TimingDataFile, timestamp, "GapToLeader", err)
-				}
+			// For the leader this is the lap they are on
+			currentDriver.GapToLeader, err = parseGap(value.(string))
+			if err != nil {
+				p.ParseTimeError(connection.TimingDataFile, timestamp, "GapToLeader", err)
 			}
-
-			// TODO - handle gap being '1 L'
-
-			// TODO -  Leaders lap number - use it somewhere else?
-
-			currentDriver.GapToLeader = t
 		}
 
 		value, exists = record["IntervalToPositionAhead"].(map[string]interface{})
@@ -116,26 +96,15 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 			interval, exists := value.(map[string]interface{})["Value"]
 
 			if exists {
-
-				strInterval := interval.(string)
-
-				if len(strInterval) > 0 &&
-					!strings.HasPrefix(strInterval, "LAP") &&
-					!strings.HasSuffix(strInterval, "L") {
-
-					t, err := parseDuration(strInterval)
-					if err != nil {
-						p.ParseTimeError(connection.TimingDataFile, timestamp, "IntervalToPositionAhead Value", err)
-					}
-
-					currentDriver.TimeDiffToPositionAhead = t
+				currentDriver.TimeDiffToPositionAhead, err = parseGap(interval.(string))
+				if err != nil {
+					p.ParseTimeError(connection.TimingDataFile, timestamp, "IntervalToPositionAhead Value", err)
 				}
-
 			}
 		} else {
 			// For races the leader doesn't have there time ahead cleared
 			if p.eventState.Type == Messages.Race && currentDriver.Position == 1 {
-				currentDriver.TimeDiffToPositionAhead = 0
+				currentDriver.TimeDiffToPositionAhead = Messages.Gap{}
 			}
 		}
 
@@ -145,36 +114,22 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 			for _, diff := range stats {
 				value, exists = diff.(map[string]interface{})["TimeDiffToPositionAhead:"].(string)
 				if exists {
-					var t time.Duration
-
-					if len(value.(string)) > 0 {
-						t, err = parseDuration(value.(string))
-						if err != nil {
-							p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToPositionAhead", err)
-						}
+					currentDriver.TimeDiffToPositionAhead, err = parseGap(value.(string))
+					if err != nil {
+						p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToPositionAhead", err)
 					}
-
-					currentDriver.TimeDiffToPositionAhead = t
 				}
 
 				value, exists = diff.(map[string]interface{})["TimeDiffToFastest"].(string)
 				if exists {
-					var t time.Duration
-
-					if len(value.(string)) > 0 {
-						t, err = parseDuration(value.(string))
-						if err != nil {
-							p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToFastest", err)
-						}
+					currentDriver.TimeDiffToFastest, err = parseGap(value.(string))
+					if err != nil {
+						p.ParseTimeError(connection.TimingDataFile, timestamp, "TimeDiffToFastest", err)
 					}
-
-					currentDriver.TimeDiffToFastest = t
 				}
 			}
 		}
 
-		// TODO - handle Status = 96, 608, 288, 800, 768, 576
-
 		// Handle NumberOfLaps
 		value, exists = record["NumberOfLaps"].(float64)
 		if exists {
@@ -232,7 +187,7 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 			case []interface{}:
 
 				for key, value2 := range sectors.([]interface{}) {
-					p.processSectorTimes(string(key), value2, &currentDriver, timestamp)
+					p.processSectorTimes(strconv.Itoa(key), value2, &currentDriver, timestamp)
 				}
 
 			default:
@@ -240,39 +195,11 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 			}
 		}
 
-		// Override location after reading if from the segments
-		value, exists = record["Stopped"]
-		if exists {
-			if value.(bool) {
-				currentDriver.Location = Messages.Stopped
-			}
+		// The car state flags are more reliable than the segments so override the location using them
+		if hasCarState {
+			p.updateLocationFromCarState(&currentDriver, timestamp)
 		}
 
-		value, exists = record["Retired"].(string)
-		if exists {
-			if value.(bool) {
-				currentDriver.Location = Messages.OutOfRace
-			}
-		}
-
-		// We use the segments to work out when we are in the pitlane
-		//
-		//inPit, exists := record["InPit"]
-		//if exists && inPit.(bool) {
-		//		currentDriver.Location = Messages.Pitlane
-		//}
-		//
-		//boolValue, exists := record["PitOut"]
-		//if exists && boolValue.(bool) {
-		//		currentDriver.Location = Messages.PitOut
-		//}
-		//
-		//// TODO - we can do this earlier if we look at the segments and update then
-		//// If we were Pit Out but now aren't then out lap
-		//if exists && !boolValue.(bool) && currentDriver.Location == Messages.PitOut {
-		//		currentDriver.Location = Messages.OutLap
-		//}
-
 		bestLapTime, exists := record["BestLapTime"].(map[string]interface{})
 		if exists {
 			var t time.Duration
@@ -305,6 +232,9 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 				}
 
 				currentDriver.LastLap = t
+				if updated, late := p.lapTime(&currentDriver, t, timestamp); late {
+					lapResult = append(lapResult, updated)
+				}
 			}
 
 			overallFastest, exists := lastLapTime["OverallFastest"]
@@ -323,33 +253,63 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 			}
 		}
 
-		speeds, exists := record["Speeds"]
+		speeds, exists := record["Speeds"].(map[string]interface{})
 		if exists {
-			// TODO - handle 'I1', 'I2', 'FL'
+			for name, speedTrap := range speeds {
+				trap, known := speedTrapLocation(name)
+				if !known {
+					p.ParseErrorf(connection.TimingDataFile, timestamp, "Unhandled speed trap '%s'", name)
+					continue
+				}
 
-			speedTrap, exists := speeds.(map[string]interface{})["ST"]
-			if exists {
-				val, exists := speedTrap.(map[string]interface{})["Value"]
+				info, exists := speedTrap.(map[string]interface{})
+				if !exists {
+					p.ParseErrorf(connection.TimingDataFile, timestamp, "Speed trap '%s' has unexpected data: %v", name, speedTrap)
+					continue
+				}
+
+				val, exists := info["Value"].(string)
 				if exists {
-					st, _ := strconv.Atoi(val.(string))
-					currentDriver.SpeedTrap = st // KM/hr
+					// Blank when the value has been cleared
+					st, _ := strconv.Atoi(val)
+					currentDriver.Speeds[trap].Speed = st // KM/hr
 				}
 
-				overallFastest, exists := speedTrap.(map[string]interface{})["OverallFastest"]
+				overallFastest, exists := info["OverallFastest"].(bool)
 				if exists {
-					currentDriver.SpeedTrapOverallFastest = overallFastest.(bool)
+					currentDriver.Speeds[trap].OverallFastest = overallFastest
 				}
 
-				personalFastest, exists := speedTrap.(map[string]interface{})["PersonalFastest"]
+				personalFastest, exists := info["PersonalFastest"].(bool)
 				if exists {
-					currentDriver.SpeedTrapPersonalFastest = personalFastest.(bool)
+					currentDriver.Speeds[trap].PersonalFastest = personalFastest
 				}
 			}
+
+			// Keep the original speed trap fields for existing users
+			currentDriver.SpeedTrap = currentDriver.Speeds[Messages.StraightTrap].Speed
+			currentDriver.SpeedTrapOverallFastest = currentDriver.Speeds[Messages.StraightTrap].OverallFastest
+			currentDriver.SpeedTrapPersonalFastest = currentDriver.Speeds[Messages.StraightTrap].PersonalFastest
 		}
 
-		knockedOut, exists := record["KnockedOut"]
-		if exists {
-			currentDriver.KnockedOutOfQualifying = knockedOut.(bool)
+		knockedOut, hasKnockedOut := record["KnockedOut"].(bool)
+		if hasKnockedOut {
+			p.knockedOutOfQualifying(&currentDriver, knockedOut)
+		}
+
+		bestLapTimes, hasBestLapTimes := record["BestLapTimes"]
+		if hasBestLapTimes {
+			p.readBestLapTimes(bestLapTimes, &currentDriver, timestamp)
+		}
+
+		if hasKnockedOut || hasBestLapTimes {
+			p.updateQualifyingResult(&currentDriver, timestamp)
+		}
+
+		// Only record laps we have seen the whole of, not the lap count jumping when we join part way through
+		if currentDriver.Lap == previousLap+1 {
+			lapResult = append(lapResult, p.completeLap(&currentDriver, timestamp))
+			p.updateStints(&currentDriver)
 		}
 
 		p.driverTimes[driverNumber] = currentDriver
@@ -357,6 +317,8 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 		result = append(result, currentDriver)
 	}
 
+	p.numberEliminations(result, timestamp)
+
 	// Quali doesn't give us gap times so we have to calculate them when the overall fastest lap changes
 	if fastestLapChanged && p.session == Messages.QualifyingSession {
 		result = make([]Messages.Timing, 0)
@@ -374,15 +336,21 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 		for x := range orderedDrivers {
 			// TODO - 2022 british quali - first driver doesn't match currentFastestLap
 			if x == 0 { //orderedDrivers[x].FastestLap == currentFastestLap || orderedDrivers[x].FastestLap == 0 {
-				orderedDrivers[x].TimeDiffToFastest = 0
-				orderedDrivers[x].TimeDiffToPositionAhead = 0
+				orderedDrivers[x].TimeDiffToFastest = Messages.Gap{}
+				orderedDrivers[x].TimeDiffToPositionAhead = Messages.Gap{}
 			} else {
 				// TODO - this value is sometimes 0 and it shouldn't be
 				if orderedDrivers[x].FastestLap > 0 {
-					orderedDrivers[x].TimeDiffToPositionAhead = orderedDrivers[x].FastestLap - orderedDrivers[x-1].FastestLap
-					orderedDrivers[x].TimeDiffToFastest = orderedDrivers[x].FastestLap - currentFastestLap
+					orderedDrivers[x].TimeDiffToPositionAhead = Messages.Gap{
+						Type: Messages.TimeGap,
+						Time: orderedDrivers[x].FastestLap - orderedDrivers[x-1].FastestLap,
+					}
+					orderedDrivers[x].TimeDiffToFastest = Messages.Gap{
+						Type: Messages.TimeGap,
+						Time: orderedDrivers[x].FastestLap - currentFastestLap,
+					}
 
-					if orderedDrivers[x].TimeDiffToFastest < 0 {
+					if orderedDrivers[x].TimeDiffToFastest.Time < 0 {
 						p.ParseErrorf(connection.TimingDataFile, timestamp, "TimeDiffToFastest < 0 '%v'", orderedDrivers[x].TimeDiffToFastest)
 					}
 				}
@@ -401,7 +369,7 @@ func (p *Parser) parseTimingData(dat map[string]interface{}, timestamp time.Time
 		}
 	}
 
-	return result, nil
+	return result, lapResult, nil
 }
 
 func (p *Parser) processSectorTimes(key string, value interface{}, driver *Messages.Timing, timestamp time.Time) {
@@ -446,6 +414,8 @@ func (p *Parser) processSectorTimes(key string, value interface{}, driver *Messa
 			}
 		}
 
+		p.lapSectorTime(driver, key, sectorTime)
+
 		switch key {
 		case "0":
 			driver.Sector1 = sectorTime
@@ -495,38 +465,20 @@ func (p *Parser) processSectorTimes(key string, value interface{}, driver *Messa
 
 func (p *Parser) updateLocation(driver *Messages.Timing, segmentState Messages.SegmentType, timestamp time.Time) {
 
+	// When the feed tells us the car state use that instead of guessing from the segments
+	if driver.Retired || driver.Stopped || driver.InPit || driver.PitOut {
+		return
+	}
+
 	// Sometimes it is none when we are on track so leave location as is
 	if segmentState == Messages.None && (driver.Location != Messages.OutLap && driver.Location != Messages.OnTrack) {
-		if driver.Location != Messages.Pitlane {
-			if p.eventState.Type == Messages.Race && p.eventState.Status == Messages.Started {
-				driver.PitStopTimes = append(driver.PitStopTimes, Messages.PitStop{
-					Lap:          driver.Lap,
-					PitlaneEntry: timestamp,
-					PitlaneExit:  time.Time{},
-				})
-			}
-		}
-
-		driver.Location = Messages.Pitlane
+		p.enterPitlane(driver, timestamp)
 	} else if segmentState == Messages.PitlaneSegment {
-		if driver.Location != Messages.Pitlane {
-			if p.eventState.Type == Messages.Race && p.eventState.Status == Messages.Started {
-				driver.PitStopTimes = append(driver.PitStopTimes, Messages.PitStop{
-					Lap:          driver.Lap,
-					PitlaneEntry: timestamp,
-					PitlaneExit:  time.Time{},
-				})
-			}
-		}
-
-		driver.Location = Messages.Pitlane
+		p.enterPitlane(driver, timestamp)
 	} else {
 		if driver.Segment[0] == Messages.PitlaneSegment || driver.Segment[1] == Messages.PitlaneSegment {
 			if driver.Location != Messages.OutLap {
-				if p.eventState.Type == Messages.Race && p.eventState.Status == Messages.Started && driver.PitStopTimes != nil {
-					driver.PitStopTimes[len(driver.PitStopTimes)-1].PitlaneExit = timestamp
-					driver.PitStopTimes[len(driver.PitStopTimes)-1].PitlaneTime = timestamp.Sub(driver.PitStopTimes[len(driver.PitStopTimes)-1].PitlaneEntry)
-				}
+				p.exitPitlane(driver, timestamp)
 			}
 
 			driver.Location = Messages.OutLap
@@ -536,6 +488,88 @@ func (p *Parser) updateLocation(driver *Messages.Timing, segmentState Messages.S
 	}
 }
 
+// Read the InPit, PitOut, Stopped and Retired flags and the status bitfield, returns true if any were present
+func (p *Parser) readCarState(record map[string]interface{}, driver *Messages.Timing) bool {
+	hasCarState := false
+
+	readFlag := func(name string, flag *bool) {
+		value, exists := record[name].(bool)
+		if exists {
+			*flag = value
+			hasCarState = true
+		}
+	}
+
+	readFlag("InPit", &driver.InPit)
+	readFlag("PitOut", &driver.PitOut)
+	readFlag("Stopped", &driver.Stopped)
+	readFlag("Retired", &driver.Retired)
+
+	// The status bits aren't documented so only the raw value is kept, the flags above give us the car state
+	status, exists := record["Status"].(float64)
+	if exists {
+		driver.Status = int(status)
+	}
+
+	return hasCarState
+}
+
+func (p *Parser) updateLocationFromCarState(driver *Messages.Timing, timestamp time.Time) {
+	switch {
+	case driver.Retired:
+		driver.Location = Messages.OutOfRace
+
+	case driver.Stopped:
+		driver.Location = Messages.Stopped
+
+	case driver.InPit:
+		p.enterPitlane(driver, timestamp)
+
+	case driver.PitOut:
+		if driver.Location == Messages.Pitlane {
+			p.exitPitlane(driver, timestamp)
+		}
+		driver.Location = Messages.PitOut
+
+	default:
+		// Flags have been cleared so work out where we are now
+		switch driver.Location {
+		case Messages.PitOut:
+			driver.Location = Messages.OutLap
+		case Messages.Stopped:
+			driver.Location = Messages.OnTrack
+		}
+	}
+}
+
+func (p *Parser) enterPitlane(driver *Messages.Timing, timestamp time.Time) {
+	if driver.Location != Messages.Pitlane {
+		p.lapPitIn(driver)
+
+		if p.eventState.Type == Messages.Race && p.eventState.Status == Messages.Started {
+			driver.PitStopTimes = append(driver.PitStopTimes, Messages.PitStop{
+				Lap:          driver.Lap,
+				PitlaneEntry: timestamp,
+				PitlaneExit:  time.Time{},
+			})
+		}
+	}
+
+	driver.Location = Messages.Pitlane
+}
+
+func (p *Parser) exitPitlane(driver *Messages.Timing, timestamp time.Time) {
+	p.lapPitOut(driver)
+
+	if p.eventState.Type == Messages.Race && p.eventState.Status == Messages.Started && driver.PitStopTimes != nil {
+		pitStop := &driver.PitStopTimes[len(driver.PitStopTimes)-1]
+		if pitStop.PitlaneExit.IsZero() {
+			pitStop.PitlaneExit = timestamp
+			pitStop.PitlaneTime = timestamp.Sub(pitStop.PitlaneEntry)
+		}
+	}
+}
+
 func (p *Parser) calcSegment(
 	key string,
 	info interface{},
@@ -588,6 +622,8 @@ func (p *Parser) calcSegment(
 		case "0":
 			// If the last segment was in the third sector then we have started a new lap so clear everything
 			if driver.PreviousSegmentIndex > (p.eventState.Sector1Segments + p.eventState.Sector2Segments) {
+				p.lapSegmentsCompleted(driver)
+
 				for y := 0; y < len(driver.Segment); y++ {
 					driver.Segment[y] = Messages.None
 				}
@@ -619,3 +655,18 @@ func (p *Parser) calcSegment(
 
 	return segmentState, useSegmentChange
 }
+
+func speedTrapLocation(name string) (Messages.SpeedTrapLocation, bool) {
+	switch name {
+	case "I1":
+		return Messages.Intermediate1Trap, true
+	case "I2":
+		return Messages.Intermediate2Trap, true
+	case "FL":
+		return Messages.FinishLineTrap, true
+	case "ST":
+		return Messages.StraightTrap, true
+	}
+
+	return Messages.StraightTrap, false
+}
@@ -0,0 +1,91 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+)
+
+func (p *Parser) parseTimingStatsData(dat map[string]interface{}, timestamp time.Time) ([]Messages.Timing, error) {
+
+	result := make([]Messages.Timing, 0)
+
+	lines, exists := dat["Lines"].(map[string]interface{})
+	if !exists {
+		return result, nil
+	}
+
+	for driverNumber, data := range lines {
+		currentDriver, exists := p.driverTimes[driverNumber]
+		if !exists {
+			continue
+		}
+
+		// TODO - also has PersonalBestLapTime and BestSectors with positions
+		record, exists := data.(map[string]interface{})
+		if !exists {
+			continue
+		}
+
+		bestSpeeds, exists := record["BestSpeeds"].(map[string]interface{})
+		if !exists {
+			continue
+		}
+
+		previousSpeeds := currentDriver.Speeds
+
+		for name, speedTrap := range bestSpeeds {
+			trap, known := speedTrapLocation(name)
+			if !known {
+				p.ParseErrorf(connection.TimingStatsFile, timestamp, "Unhandled speed trap '%s'", name)
+				continue
+			}
+
+			info, exists := speedTrap.(map[string]interface{})
+			if !exists {
+				p.ParseErrorf(connection.TimingStatsFile, timestamp, "Speed trap '%s' has unexpected data: %v", name, speedTrap)
+				continue
+			}
+
+			value, exists := info["Value"].(string)
+			if exists {
+				speed, _ := strconv.Atoi(value)
+				currentDriver.Speeds[trap].SessionBest = speed
+			}
+
+			position, exists := info["Position"].(float64)
+			if exists {
+				currentDriver.Speeds[trap].SessionBestPosition = int(position)
+			}
+		}
+
+		// Only send drivers whose best speeds or rankings have changed
+		if currentDriver.Speeds == previousSpeeds {
+			continue
+		}
+
+		currentDriver.Timestamp = timestamp
+		p.driverTimes[driverNumber] = currentDriver
+
+		result = append(result, currentDriver)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,226 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package parser
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+)
+
+// Don't estimate the lap distance for telemetry from a location older than this
+const maxLapDistanceAge = 2 * time.Second
+
+// Where a car was on the lap at the time of its last location
+type lapPosition struct {
+	timestamp time.Time
+	distance  float64
+}
+
+// Where to save the track map once it has everything
+func (p *Parser) SetTrackMapStore(folder string, track string, year int) {
+	p.trackMapLock.Lock()
+	defer p.trackMapLock.Unlock()
+
+	p.trackMapFolder = folder
+	p.trackMapTrack = track
+	p.trackMapYear = year
+}
+
+// Use an existing track map to work out lap distances rather than waiting for one to be built
+func (p *Parser) UseTrackMap(existing trackMap.TrackMap) {
+	p.trackMapLock.Lock()
+	defer p.trackMapLock.Unlock()
+
+	p.lapDistance = trackMap.CreateLapDistance(existing.Outline)
+	p.pitLaneDistance = trackMap.CreatePitLaneDistance(p.lapDistance, existing.PitLane)
+	p.trackMapExisting = true
+	p.sectorFlags.segments = marshalSectorSegments(existing)
+}
+
+// Locations, telemetry and car samples have the lap distance and intervals, lap telemetry and corners are worked out
+// from it so they all need a track outline
+func (p *Parser) needsLapDistance() bool {
+	return p.requestedData&TrackMap == TrackMap || p.requestedData&Intervals == Intervals ||
+		p.requestedData&Location == Location || p.requestedData&LocationFrames == LocationFrames ||
+		p.requestedData&Telemetry == Telemetry || p.requestedData&TelemetryFrames == TelemetryFrames ||
+		p.requestedData&CarSamples == CarSamples || p.bufferLapTelemetry()
+}
+
+// Build a track outline if we need one and don't already have it
+func (p *Parser) buildTrackMap() bool {
+	p.trackMapLock.Lock()
+	defer p.trackMapLock.Unlock()
+
+	if p.trackMapExisting {
+		return false
+	}
+
+	return p.needsLapDistance()
+}
+
+func (p *Parser) TrackMap() trackMap.TrackMap {
+	result := p.trackMap.TrackMap()
+
+	p.trackMapLock.Lock()
+	result.Track = p.trackMapTrack
+	result.Year = p.trackMapYear
+	p.trackMapLock.Unlock()
+
+	return result
+}
+
+func (p *Parser) trackMapTiming(timing []Messages.Timing) {
+	p.trackMap.SetSegments(p.eventState.Sector1Segments, p.eventState.Sector2Segments, p.eventState.Sector3Segments)
+
+	for _, driver := range timing {
+		p.trackMap.AddTiming(driver)
+	}
+
+	p.saveTrackMap()
+}
+
+func (p *Parser) trackMapLocations(locations []Messages.Location) {
+	for _, location := range locations {
+		p.trackMap.AddLocation(location)
+	}
+
+	p.trackMapLock.Lock()
+	if p.lapDistance == nil {
+		p.lapDistance = trackMap.CreateLapDistance(p.trackMap.Outline())
+	}
+	if p.pitLaneDistance == nil && p.lapDistance != nil {
+		p.pitLaneDistance = trackMap.CreatePitLaneDistance(p.lapDistance, p.trackMap.PitLane())
+	}
+	p.trackMapLock.Unlock()
+
+	p.saveTrackMap()
+}
+
+func (p *Parser) saveTrackMap() {
+	if p.trackMapSaved || !p.trackMap.Complete() {
+		return
+	}
+	p.trackMapSaved = true
+
+	result := p.TrackMap()
+
+	p.trackMapLock.Lock()
+	folder := p.trackMapFolder
+	p.trackMapLock.Unlock()
+
+	if len(folder) == 0 || len(result.Track) == 0 {
+		return
+	}
+
+	err := result.Save(folder)
+	if err != nil {
+		p.log.Errorf("Saving track map for '%s' %d: %v", result.Track, result.Year, err)
+	}
+}
+
+func (p *Parser) currentLapDistance() *trackMap.LapDistance {
+	p.trackMapLock.Lock()
+	defer p.trackMapLock.Unlock()
+	return p.lapDistance
+}
+
+func (p *Parser) currentPitLaneDistance() *trackMap.PitLaneDistance {
+	p.trackMapLock.Lock()
+	defer p.trackMapLock.Unlock()
+	return p.pitLaneDistance
+}
+
+func (p *Parser) inPitLane(driverNumber int) bool {
+	driver := p.driverTimes[strconv.Itoa(driverNumber)]
+	return driver.InPit || driver.Location == Messages.Pitlane
+}
+
+func (p *Parser) locationLapDistances(locations []Messages.Location) {
+	lapDistance := p.currentLapDistance()
+	if lapDistance == nil {
+		return
+	}
+	pitLaneDistance := p.currentPitLaneDistance()
+
+	for x := range locations {
+		if locations[x].Status == Messages.PositionNoFix {
+			delete(p.lapPositions, locations[x].DriverNumber)
+			continue
+		}
+
+		point := trackMap.Point{X: locations[x].X, Y: locations[x].Y, Z: locations[x].Z}
+		inPitLane := false
+		if pitLaneDistance != nil && p.inPitLane(locations[x].DriverNumber) {
+			// Use how far along the pit lane the car is rather than the nearest part of the track
+			locations[x].LapDistance, locations[x].LapFraction, inPitLane = pitLaneDistance.Distance(point)
+		}
+		if !inPitLane {
+			locations[x].LapDistance, locations[x].LapFraction = p.lapDistanceFor(lapDistance, locations[x].DriverNumber, point)
+		}
+
+		p.lapPositions[locations[x].DriverNumber] = lapPosition{
+			timestamp: locations[x].Timestamp,
+			distance:  locations[x].LapDistance,
+		}
+	}
+}
+
+// Telemetry doesn't have a location so move on from the last one at the speed of the car. Returns false and leaves
+// the distance at 0 when it isn't known because there is no track map or no recent location for the car.
+func (p *Parser) telemetryLapDistance(lapDistance *trackMap.LapDistance, telemetry *Messages.Telemetry) bool {
+	if lapDistance == nil {
+		return false
+	}
+
+	position, exists := p.lapPositions[telemetry.DriverNumber]
+	if !exists {
+		return false
+	}
+
+	elapsed := telemetry.Timestamp.Sub(position.timestamp)
+	if elapsed > maxLapDistanceAge || elapsed < -maxLapDistanceAge {
+		return false
+	}
+
+	metresPerSecond := float64(telemetry.Speed) / 3.6
+	telemetry.LapDistance, telemetry.LapFraction = lapDistance.Wrap(position.distance + metresPerSecond*elapsed.Seconds())
+	return true
+}
+
+func (p *Parser) carSampleLapDistances(samples []Messages.CarSample) {
+	lapDistance := p.currentLapDistance()
+	if lapDistance == nil {
+		return
+	}
+
+	for x := range samples {
+		samples[x].LapDistance, samples[x].LapFraction = p.lapDistanceFor(lapDistance, samples[x].DriverNumber,
+			trackMap.Point{X: samples[x].X, Y: samples[x].Y, Z: samples[x].Z})
+	}
+}
+
+// Look near where the car last was so it doesn't jump to another part of the lap where the track crosses over
+func (p *Parser) lapDistanceFor(lapDistance *trackMap.LapDistance, driverNumber int, point trackMap.Point) (float64, float64) {
+	position, exists := p.lapPositions[driverNumber]
+	if !exists {
+		return lapDistance.Distance(point)
+	}
+
+	return lapDistance.DistanceNear(point, position.distance)
+}
@@ -0,0 +1,73 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/analysis"
+	"testing"
+	"time"
+)
+
+// A lap at a constant speed with braking at 1000m
+func constantSpeedLap(driverNumber int, speed float32) analysis.Lap {
+	lap := analysis.Lap{DriverNumber: driverNumber, Lap: 1}
+	metresPerSecond := float64(speed) / 3.6
+
+	for distance := 0.0; distance <= 2000; distance += 20 {
+		sample := analysis.Sample{
+			Time:     time.Duration(distance / metresPerSecond * float64(time.Second)),
+			Distance: distance,
+			Speed:    speed,
+			Throttle: 100,
+		}
+		if distance >= 1000 && distance < 1100 {
+			sample.Throttle = 0
+			sample.Brake = 100
+		}
+		lap.Samples = append(lap.Samples, sample)
+	}
+
+	return lap
+}
+
+func TestCompareLaps(t *testing.T) {
+	first := constantSpeedLap(1, 360)
+	second := constantSpeedLap(2, 180)
+
+	comparison := analysis.CompareLaps(first, second, 10)
+
+	if len(comparison.Points) != 201 {
+		t.Fatalf("Expected 201 points but got %d", len(comparison.Points))
+	}
+
+	// Half the speed takes twice as long so the second car loses 10s over the first 1000m
+	for _, point := range comparison.Points {
+		if point.Distance != 1000 {
+			continue
+		}
+
+		if point.Delta != 10*time.Second {
+			t.Errorf("Expected a delta of 10s at 1000m but got %v", point.Delta)
+		}
+		if point.SpeedDelta != -180 {
+			t.Errorf("Expected a speed delta of -180 but got %v", point.SpeedDelta)
+		}
+	}
+
+	if len(comparison.FirstBrakingPoints) != 1 || comparison.FirstBrakingPoints[0].Distance != 1000 {
+		t.Errorf("Expected one braking point at 1000m but got %v", comparison.FirstBrakingPoints)
+	}
+}
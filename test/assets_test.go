@@ -0,0 +1,102 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/f1log"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("image"))
+	}))
+}
+
+func TestHeadshotCachedInsideCache(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	folder := t.TempDir()
+	cache := filepath.Join(folder, "cache")
+
+	log := f1log.CreateLog()
+	log.SetLogOutput(io.Discard)
+	assets := connection.CreateAssetStore(server.URL+"/", cache, log)
+
+	// The path is cleaned by the client but the cache file mustn't be built from it
+	headshot, err := assets.DriverHeadshot(server.URL + "/%2E%2E/%2E%2E/escape.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(headshot) != "image" {
+		t.Errorf("Unexpected headshot data '%s'", headshot)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(folder, "*"))
+	if len(files) != 1 || files[0] != cache {
+		t.Errorf("Expected only the cache folder to be created but got %v", files)
+	}
+
+	cached, _ := filepath.Glob(filepath.Join(cache, "Headshots", "*"))
+	if len(cached) != 1 {
+		t.Errorf("Expected one cached headshot but got %v", cached)
+	}
+}
+
+func TestTeamRadioOutsideCache(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	folder := t.TempDir()
+
+	log := f1log.CreateLog()
+	log.SetLogOutput(io.Discard)
+	assets := connection.CreateAssetStore(server.URL+"/", filepath.Join(folder, "cache"), log)
+
+	_, err := assets.TeamRadio("../../escape.mp3")
+	if err == nil {
+		t.Error("Expected an error for a file outside of the cache")
+	}
+
+	if _, err = os.Stat(filepath.Join(folder, "escape.mp3")); !os.IsNotExist(err) {
+		t.Error("File was written outside of the cache")
+	}
+}
+
+func TestAssetStatusWithoutCache(t *testing.T) {
+	server := createTestServer()
+	defer server.Close()
+
+	log := f1log.CreateLog()
+	log.SetLogOutput(io.Discard)
+	assets := connection.CreateAssetStore(server.URL+"/", "", log)
+
+	_, err := assets.DriverHeadshot(server.URL + "/missing.png")
+	if err == nil {
+		t.Error("Expected an error for a missing headshot")
+	}
+}
@@ -0,0 +1,120 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"reflect"
+	"testing"
+)
+
+func telemetrySession(requestedData parser.DataSource) *testSession {
+	session := createTestSession(requestedData, Messages.RaceSession)
+	session.parser.SelectTelemetrySources([]int{1})
+	return session
+}
+
+func TestTelemetryChannels(t *testing.T) {
+	session := telemetrySession(parser.Telemetry)
+	session.process(carDataMessage(10, 1, `"0":11000,"2":280,"3":8,"4":99,"5":0,"45":12`))
+
+	if len(session.output.telemetry) != 1 {
+		t.Fatalf("Expected 1 sample but got %d", len(session.output.telemetry))
+	}
+
+	telemetry := session.output.telemetry[0]
+	if telemetry.RPM != 11000 || telemetry.Speed != 280 || telemetry.Gear != 8 || telemetry.Throttle != 99 ||
+		telemetry.Brake != 0 || telemetry.DRSRaw != 12 {
+		t.Errorf("Unexpected telemetry %+v", telemetry)
+	}
+	if telemetry.Channels != nil {
+		t.Errorf("Expected no unknown channels but got %v", telemetry.Channels)
+	}
+}
+
+// Channels we don't know are passed through by id and ids can be moved to a new channel
+func TestTelemetryUnknownChannels(t *testing.T) {
+	session := telemetrySession(parser.Telemetry)
+	session.process(carDataMessage(10, 1, `"0":11000,"2":280,"7":1.5,"46":3`))
+
+	telemetry := session.output.telemetry[0]
+	if !reflect.DeepEqual(telemetry.Channels, map[int]float64{7: 1.5, 46: 3}) {
+		t.Errorf("Unexpected unknown channels %v", telemetry.Channels)
+	}
+
+	channels := parser.DefaultTelemetryChannels()
+	channels[46] = Messages.GearChannel
+	session.parser.SetTelemetryChannels(channels)
+	session.process(carDataMessage(11, 1, `"0":11000,"2":280,"7":1.5,"46":3`))
+
+	telemetry = session.output.telemetry[1]
+	if telemetry.Gear != 3 || !reflect.DeepEqual(telemetry.Channels, map[int]float64{7: 1.5}) {
+		t.Errorf("Expected channel 46 to be the gear but got %d and %v", telemetry.Gear, telemetry.Channels)
+	}
+}
+
+func TestTelemetryDRSStates(t *testing.T) {
+	tests := []struct {
+		value int
+		state Messages.CarDRSState
+		open  bool
+	}{
+		{0, Messages.CarDRSOff, false},
+		{1, Messages.CarDRSOff, false},
+		{8, Messages.CarDRSAvailable, false},
+		// Armed has always been counted as open for the flags
+		{10, Messages.CarDRSArmed, true},
+		{12, Messages.CarDRSOpen, true},
+		{14, Messages.CarDRSOpen, true},
+		{9, Messages.CarDRSUnknown, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.state.String(), func(t *testing.T) {
+			session := telemetrySession(parser.Telemetry | parser.Timing)
+			session.process(
+				driverListMessage(sessionTime(0), 1),
+				carDataMessage(10, 1, fmt.Sprintf(`"0":11000,"45":%d`, test.value)))
+
+			telemetry := session.output.telemetry[0]
+			if telemetry.DRSRaw != test.value || telemetry.DRSState != test.state || telemetry.DRS != test.open {
+				t.Errorf("Value %d gave %d %s %v, expected %s %v", test.value, telemetry.DRSRaw, telemetry.DRSState,
+					telemetry.DRS, test.state, test.open)
+			}
+			if lastTiming(session, 1).DRSOpen != test.open {
+				t.Errorf("Value %d gave DRS open %v, expected %v", test.value, lastTiming(session, 1).DRSOpen, test.open)
+			}
+		})
+	}
+}
+
+// New regulations can change the DRS values without a new release
+func TestTelemetrySetDRSStates(t *testing.T) {
+	session := telemetrySession(parser.Telemetry)
+	session.parser.SetDRSStates(map[int]Messages.CarDRSState{2: Messages.CarDRSOpen})
+	session.process(
+		carDataMessage(10, 1, `"0":11000,"45":2`),
+		carDataMessage(11, 1, `"0":11000,"45":12`))
+
+	if session.output.telemetry[0].DRSState != Messages.CarDRSOpen || !session.output.telemetry[0].DRS {
+		t.Errorf("Expected 2 to be open but got %s", session.output.telemetry[0].DRSState)
+	}
+	if session.output.telemetry[1].DRSState != Messages.CarDRSUnknown || session.output.telemetry[1].DRS {
+		t.Errorf("Expected 12 to be unknown but got %s", session.output.telemetry[1].DRSState)
+	}
+}
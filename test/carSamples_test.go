@@ -0,0 +1,90 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func carSampleMessages(driver int, from float64, to float64) []connection.Payload {
+	var result []connection.Payload
+	for seconds := from; seconds <= to; seconds += 0.25 {
+		timestamp := sessionTime(seconds)
+		result = append(result,
+			compressedMessage(connection.PositionFile, timestamp, fmt.Sprintf(
+				`{"Position":[{"Timestamp":"%s","Entries":{"%d":{"Status":"OnTrack","X":%f,"Y":0,"Z":0}}}]}`,
+				timestamp.Format("2006-01-02T15:04:05.999Z"), driver, seconds*10)),
+			compressedMessage(connection.CarDataFile, timestamp, fmt.Sprintf(
+				`{"Entries":[{"Utc":"%s","Cars":{"%d":{"Channels":{"0":11000,"2":%f,"3":6}}}}]}`,
+				timestamp.Format("2006-01-02T15:04:05.999Z"), driver, 200+seconds)))
+	}
+	return result
+}
+
+// Samples are created for every car without selecting any telemetry
+func TestCarSamplesWithoutSelection(t *testing.T) {
+	session := createTestSession(parser.CarSamples, Messages.RaceSession)
+	session.process(carSampleMessages(44, 1, 3)...)
+
+	if len(session.output.samples) == 0 {
+		t.Fatalf("No car samples sent, log: %s", session.log.String())
+	}
+
+	for _, sample := range session.output.samples {
+		if sample.DriverNumber != 44 {
+			t.Fatalf("Sample for driver %d", sample.DriverNumber)
+		}
+
+		// X moves 10 per second and the speed 1 per second so both come from the same time
+		seconds := sample.Timestamp.Sub(testSessionStart).Seconds()
+		if diff := sample.X - seconds*10; diff > 0.001 || diff < -0.001 {
+			t.Errorf("At %.2fs X is %f", seconds, sample.X)
+		}
+		if diff := float64(sample.Speed) - (200 + seconds); diff > 0.001 || diff < -0.001 {
+			t.Errorf("At %.2fs speed is %f", seconds, sample.Speed)
+		}
+	}
+}
+
+// Samples start again when the telemetry returns after a gap with only locations
+func TestCarSamplesAfterTelemetryGap(t *testing.T) {
+	session := createTestSession(parser.CarSamples, Messages.RaceSession)
+	session.process(carSampleMessages(44, 1, 2)...)
+
+	var locations []connection.Payload
+	for x, msg := range carSampleMessages(44, 2.25, 20) {
+		if x%2 == 0 {
+			locations = append(locations, msg)
+		}
+	}
+	session.process(locations...)
+
+	before := len(session.output.samples)
+	session.process(carSampleMessages(44, 20.25, 22)...)
+
+	if len(session.output.samples) <= before {
+		t.Fatal("No car samples after the telemetry returned")
+	}
+	for _, sample := range session.output.samples[before:] {
+		if sample.Timestamp.Before(sessionTime(20.25)) {
+			t.Errorf("Sample at %s during the gap", sample.Timestamp)
+		}
+	}
+}
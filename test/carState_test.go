@@ -0,0 +1,85 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestCarState(t *testing.T) {
+	tests := []struct {
+		name     string
+		updates  []string
+		location Messages.CarLocation
+		inPit    bool
+		pitOut   bool
+		stopped  bool
+		retired  bool
+		status   int
+	}{
+		{"in pit", []string{`{"InPit":true,"Status":96}`}, Messages.Pitlane, true, false, false, false, 96},
+		{"pit out", []string{`{"InPit":true}`, `{"InPit":false,"PitOut":true}`}, Messages.PitOut, false, true, false, false, 0},
+		{"out lap", []string{`{"InPit":true}`, `{"InPit":false,"PitOut":true}`, `{"PitOut":false}`}, Messages.OutLap, false, false, false, false, 0},
+		{"stopped", []string{`{"Stopped":true,"Status":608}`}, Messages.Stopped, false, false, true, false, 608},
+		{"moving again", []string{`{"Stopped":true}`, `{"Stopped":false}`}, Messages.OnTrack, false, false, false, false, 0},
+		{"retired", []string{`{"Stopped":true}`, `{"Retired":true,"Status":800}`}, Messages.OutOfRace, false, false, true, true, 800},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+
+			messages := []connection.Payload{
+				driverListMessage(sessionTime(0), 1),
+				timingMessage(sessionTime(1), 1, `{"NumberOfLaps":3}`),
+			}
+			for x, update := range test.updates {
+				messages = append(messages, timingMessage(sessionTime(float64(10+x)), 1, update))
+			}
+			session.process(messages...)
+
+			timing := session.output.timing[len(session.output.timing)-1]
+			if timing.Location != test.location {
+				t.Errorf("Location is %s, expected %s", timing.Location, test.location)
+			}
+			if timing.InPit != test.inPit || timing.PitOut != test.pitOut || timing.Stopped != test.stopped ||
+				timing.Retired != test.retired {
+				t.Errorf("In pit %v, pit out %v, stopped %v, retired %v", timing.InPit, timing.PitOut, timing.Stopped, timing.Retired)
+			}
+			if timing.Status != test.status {
+				t.Errorf("Status is %d, expected %d", timing.Status, test.status)
+			}
+		})
+	}
+}
+
+// A car that retired in the previous session isn't still retired
+func TestCarStateNewSession(t *testing.T) {
+	session := createTestSession(parser.Timing|parser.Event, Messages.RaceSession)
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(0), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Qualifying"}`),
+		driverListMessage(sessionTime(0), 1),
+		timingMessage(sessionTime(10), 1, `{"Retired":true,"Status":800}`),
+		message(connection.SessionInfoFile, sessionTime(7200), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race"}`))
+
+	timing := session.output.timing[len(session.output.timing)-1]
+	if timing.Retired || timing.Status != 0 || timing.Location == Messages.OutOfRace {
+		t.Errorf("Car is still retired: %v %d %s", timing.Retired, timing.Status, timing.Location)
+	}
+}
@@ -0,0 +1,118 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+	"time"
+)
+
+func raceResultMessages() []connection.Payload {
+	return []connection.Payload{
+		message(connection.SessionInfoFile, sessionTime(0), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race"}`),
+		driverListMessage(sessionTime(0), 1, 2, 3, 4, 5, 6),
+		timingMessage(sessionTime(1), 1, `{"Position":"1","NumberOfLaps":9}`),
+		timingMessage(sessionTime(1), 2, `{"Position":"2","NumberOfLaps":9}`),
+		timingMessage(sessionTime(1), 3, `{"Position":"3","NumberOfLaps":9}`),
+		timingMessage(sessionTime(1), 4, `{"Position":"4","NumberOfLaps":9}`),
+		timingMessage(sessionTime(1), 6, `{"Position":"6","NumberOfLaps":4}`),
+		timingMessage(sessionTime(90), 1, `{"NumberOfLaps":10,"LastLapTime":{"Value":"1:30.000"}}`),
+		timingMessage(sessionTime(95), 2, `{"NumberOfLaps":10,"GapToLeader":"+5.000"}`),
+		timingMessage(sessionTime(96), 3, `{"GapToLeader":"1L"}`),
+		timingMessage(sessionTime(97), 4, `{"NumberOfLaps":10,"GapToLeader":"+7.000"}`),
+		timingMessage(sessionTime(98), 6, `{"Retired":true}`),
+		raceControlMessage(sessionTime(99), "Other", "FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 2 (D02) - CAUSING A COLLISION", ""),
+		raceControlMessage(sessionTime(99), "Other", "FIA STEWARDS: DRIVE THROUGH PENALTY FOR CAR 3 (D03) - FALSE START", ""),
+		raceControlMessage(sessionTime(100), "Other", "CAR 4 (NOR) DISQUALIFIED - TECHNICAL INFRINGEMENT", ""),
+		message(connection.SessionStatusFile, sessionTime(120), `{"Status":"Finished"}`),
+	}
+}
+
+// Only asking for the classification still needs the timing and race control messages
+func TestClassification(t *testing.T) {
+	session := createTestSession(parser.Classification, Messages.RaceSession)
+	session.process(raceResultMessages()...)
+
+	if len(session.output.classifications) != 1 {
+		t.Fatalf("Expected 1 classification but got %d, log: %s", len(session.output.classifications), session.log.String())
+	}
+
+	expected := []struct {
+		number   int
+		status   Messages.ClassificationStatus
+		lapsDown int
+		gap      time.Duration
+	}{
+		{1, Messages.ClassifiedFinished, 0, 0},
+		{2, Messages.ClassifiedFinished, 0, 5 * time.Second},
+		{3, Messages.ClassifiedLapped, 1, 0},
+		{6, Messages.ClassifiedDNF, 0, 0},
+		{5, Messages.ClassifiedDNS, 0, 0},
+		{4, Messages.ClassifiedDSQ, 0, 7 * time.Second},
+	}
+
+	entries := session.output.classifications[0].Entries
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries but got %d", len(expected), len(entries))
+	}
+
+	for x, entry := range entries {
+		if entry.Position != x+1 || entry.Number != expected[x].number || entry.Status != expected[x].status ||
+			entry.LapsDown != expected[x].lapsDown || entry.Gap.Time != expected[x].gap {
+			t.Errorf("Position %d is car %d %s %d laps down %s, expected car %d %s %d laps down %s", entry.Position,
+				entry.Number, entry.Status, entry.LapsDown, entry.Gap.Time,
+				expected[x].number, expected[x].status, expected[x].lapsDown, expected[x].gap)
+		}
+	}
+
+	// The winner's time is only known if we saw every lap
+	if entries[0].Laps != 10 || entries[0].TotalTime != 0 {
+		t.Errorf("Winner did %d laps in %s", entries[0].Laps, entries[0].TotalTime)
+	}
+
+	if entries[1].Penalties.TimePenaltySeconds != 5 || entries[2].Penalties.DriveThroughs != 1 {
+		t.Errorf("Penalties are %+v and %+v", entries[1].Penalties, entries[2].Penalties)
+	}
+
+	if len(session.parser.FinalClassification().Entries) != len(expected) {
+		t.Errorf("Final classification has %d entries", len(session.parser.FinalClassification().Entries))
+	}
+}
+
+// A disqualification from the previous session doesn't carry over
+func TestClassificationNewSession(t *testing.T) {
+	session := createTestSession(parser.Classification, Messages.RaceSession)
+	session.process(raceResultMessages()...)
+
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(7200), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Practice 1"}`),
+		timingMessage(sessionTime(7201), 4, `{"Position":"1","NumberOfLaps":1}`))
+
+	if len(session.parser.FinalClassification().Entries) != 0 {
+		t.Errorf("Classification from the previous session is still available")
+	}
+
+	session.process(message(connection.SessionStatusFile, sessionTime(9000), `{"Status":"Finalised"}`))
+
+	for _, entry := range session.output.classifications[len(session.output.classifications)-1].Entries {
+		if entry.Number == 4 && entry.Status == Messages.ClassifiedDSQ {
+			t.Errorf("Car 4 is still disqualified")
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/analysis"
+	"testing"
+	"time"
+)
+
+// A 3km lap at 300km/h with a sample every 10m taking 100ms. There is a braking zone from 1000m with the apex at
+// 1100m, a corner taken with a lift from 2000m and a small lift at 2500m that hardly loses any speed.
+func cornersLap() analysis.Lap {
+	lap := analysis.Lap{DriverNumber: 1, Lap: 1}
+
+	for distance := 0.0; distance <= 3000; distance += 10 {
+		sample := analysis.Sample{
+			Time:     time.Duration(distance) * 10 * time.Millisecond,
+			Distance: distance,
+			Speed:    300,
+			Gear:     8,
+			Throttle: 100,
+		}
+
+		switch {
+		case distance >= 1000 && distance < 1100:
+			sample.Brake = 100
+			sample.Throttle = 0
+			sample.Speed = float32(300 - (distance-1000)*2)
+			sample.Gear = 4
+		case distance >= 1100 && distance < 1200:
+			sample.Throttle = 30
+			sample.Speed = float32(100 + distance - 1100)
+			sample.Gear = 3
+		case distance == 1200:
+			sample.Speed = 200
+			sample.Gear = 5
+		case distance >= 2000 && distance < 2050:
+			sample.Throttle = 50
+			sample.Speed = float32(300 - (distance - 2000))
+			sample.Gear = 7
+		case distance >= 2050 && distance < 2100:
+			sample.Throttle = 50
+			sample.Speed = float32(250 + distance - 2050)
+			sample.Gear = 7
+		case distance >= 2500 && distance < 2550:
+			sample.Throttle = 80
+			sample.Speed = 295
+		}
+
+		lap.Samples = append(lap.Samples, sample)
+	}
+
+	return lap
+}
+
+func TestCorners(t *testing.T) {
+	expected := []Messages.Corner{
+		{
+			Number:       1,
+			BrakingStart: 1000,
+			Apex:         1100,
+			Exit:         1200,
+			EntrySpeed:   300,
+			MinimumSpeed: 100,
+			ExitSpeed:    200,
+			Gear:         3,
+			Time:         2 * time.Second,
+		},
+		{
+			Number:       2,
+			BrakingStart: 2000,
+			Apex:         2050,
+			Exit:         2100,
+			EntrySpeed:   300,
+			MinimumSpeed: 250,
+			ExitSpeed:    300,
+			Gear:         7,
+			Time:         time.Second,
+		},
+	}
+
+	corners := analysis.Corners(cornersLap())
+
+	if len(corners) != len(expected) {
+		t.Fatalf("Expected %d corners but got %d: %+v", len(expected), len(corners), corners)
+	}
+
+	for x := range expected {
+		if corners[x] != expected[x] {
+			t.Errorf("Corner %d is %+v, expected %+v", x+1, corners[x], expected[x])
+		}
+	}
+}
+
+// A lap without slowing down has no corners
+func TestCornersFlatOut(t *testing.T) {
+	lap := analysis.Lap{DriverNumber: 1, Lap: 1}
+	for distance := 0.0; distance <= 1000; distance += 10 {
+		lap.Samples = append(lap.Samples, analysis.Sample{Distance: distance, Speed: 300, Throttle: 100})
+	}
+
+	if corners := analysis.Corners(lap); len(corners) != 0 {
+		t.Errorf("Expected no corners but got %+v", corners)
+	}
+}
+
+// Braking where the lap ends is still a corner that finishes at the last sample
+func TestCornersBrakingAtTheEnd(t *testing.T) {
+	lap := analysis.Lap{DriverNumber: 1, Lap: 1}
+	for distance := 0.0; distance <= 1000; distance += 10 {
+		sample := analysis.Sample{Distance: distance, Speed: 300, Throttle: 100, Gear: 8}
+		if distance >= 900 {
+			sample.Throttle = 0
+			sample.Brake = 100
+			sample.Speed = float32(300 - (distance - 900))
+			sample.Gear = 6
+		}
+		lap.Samples = append(lap.Samples, sample)
+	}
+
+	corners := analysis.Corners(lap)
+	if len(corners) != 1 {
+		t.Fatalf("Expected 1 corner but got %d", len(corners))
+	}
+	if corners[0].BrakingStart != 900 || corners[0].Exit != 1000 || corners[0].MinimumSpeed != 200 {
+		t.Errorf("Corner is %+v", corners[0])
+	}
+}
+
+// The same lap with a lift at 500m that only happens on some laps
+func cornersLapWithLift() analysis.Lap {
+	lap := cornersLap()
+	for x := range lap.Samples {
+		if lap.Samples[x].Distance >= 500 && lap.Samples[x].Distance < 550 {
+			lap.Samples[x].Throttle = 50
+			lap.Samples[x].Speed = float32(300 - (lap.Samples[x].Distance - 500))
+		}
+	}
+	return lap
+}
+
+// A lift on only one of the laps mustn't change the numbers of the corners after it
+func TestCornerNumbersWithDifferentLifts(t *testing.T) {
+	numbers := analysis.CornerNumbers{}
+
+	first, added := numbers.Number(analysis.Corners(cornersLap()), 3000)
+	if !added {
+		t.Error("Expected the corners on the first lap to be added")
+	}
+	if len(first) != 2 || first[0].Number != 1 || first[1].Number != 2 {
+		t.Fatalf("First lap corners are %+v", first)
+	}
+
+	second, added := numbers.Number(analysis.Corners(cornersLapWithLift()), 3000)
+	if !added {
+		t.Error("Expected the lift to be a new corner")
+	}
+	if len(second) != 3 {
+		t.Fatalf("Expected 3 corners but got %d: %+v", len(second), second)
+	}
+
+	// The first lap is renumbered because the lift is before its corners
+	first, added = numbers.Number(first, 3000)
+	if added {
+		t.Error("Renumbering the first lap shouldn't add any corners")
+	}
+
+	for _, corner := range first {
+		matched := false
+		for _, other := range second {
+			if other.Apex == corner.Apex {
+				matched = true
+				if other.Number != corner.Number {
+					t.Errorf("Corner at %.0fm is %d on the first lap and %d on the second", corner.Apex, corner.Number, other.Number)
+				}
+			}
+		}
+		if !matched {
+			t.Errorf("Corner at %.0fm isn't on the second lap", corner.Apex)
+		}
+	}
+
+	if second[0].Apex != 540 || second[0].Number != 1 || first[0].Number != 2 || first[1].Number != 3 {
+		t.Errorf("Corners aren't in order around the track, first lap %+v, second lap %+v", first, second)
+	}
+
+	// A lap without the lift keeps the numbers from the track
+	third, added := numbers.Number(analysis.Corners(cornersLap()), 3000)
+	if added || len(third) != 2 || third[0].Number != 2 || third[1].Number != 3 {
+		t.Errorf("Third lap corners are %+v", third)
+	}
+}
+
+// Apexes either side of the line are the same corner
+func TestCornerNumbersAcrossTheLine(t *testing.T) {
+	numbers := analysis.CornerNumbers{}
+
+	numbers.Number([]Messages.Corner{{Apex: 10}, {Apex: 1500}}, 3000)
+	corners, added := numbers.Number([]Messages.Corner{{Apex: 1510}, {Apex: 2990}}, 3000)
+
+	if added || corners[0].Number != 2 || corners[1].Number != 1 {
+		t.Errorf("Expected the corners to match the first lap but got %+v", corners)
+	}
+}
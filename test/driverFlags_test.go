@@ -0,0 +1,129 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func blueFlagMessage(seconds float64, driver int) connection.Payload {
+	return raceControlMessage(sessionTime(seconds), "Flag", fmt.Sprintf("WAVED BLUE FLAG FOR CAR %d (D%02d)", driver, driver),
+		fmt.Sprintf(`"Flag":"BLUE","Scope":"Driver","RacingNumber":"%d"`, driver))
+}
+
+func TestDriverFlags(t *testing.T) {
+	tests := []struct {
+		name               string
+		messages           []connection.Payload
+		blueFlag           bool
+		blueFlags          int
+		blackAndWhiteFlag  bool
+		blackAndWhiteFlags int
+	}{
+		{"blue flag", []connection.Payload{blueFlagMessage(10, 4)}, true, 1, false, 0},
+		{"blue flag from the message", []connection.Payload{
+			raceControlMessage(sessionTime(10), "Flag", "WAVED BLUE FLAG FOR CAR 4 (NOR) TIMED AT 15:00:10", `"Flag":"BLUE"`)},
+			true, 1, false, 0},
+		{"repeated blue flags", []connection.Payload{blueFlagMessage(10, 4), blueFlagMessage(20, 4)}, true, 2, false, 0},
+		{"black and white flag", []connection.Payload{
+			raceControlMessage(sessionTime(10), "Flag", "BLACK AND WHITE FLAG FOR CAR 4 (NOR) - TRACK LIMITS",
+				`"Flag":"BLACK AND WHITE","Scope":"Driver","RacingNumber":"4"`)},
+			false, 0, true, 1},
+		{"other driver", []connection.Payload{
+			raceControlMessage(sessionTime(10), "Flag", "WAVED BLUE FLAG FOR CAR 1 (VER) TIMED AT 15:00:10",
+				`"Flag":"BLUE","Scope":"Driver","RacingNumber":"1"`)},
+			false, 0, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+			session.process(append([]connection.Payload{driverListMessage(sessionTime(0), 1, 4)}, test.messages...)...)
+
+			timing := lastTiming(session, 4)
+			if timing.BlueFlag != test.blueFlag || timing.BlueFlags != test.blueFlags {
+				t.Errorf("Blue flag %v %d, expected %v %d", timing.BlueFlag, timing.BlueFlags, test.blueFlag, test.blueFlags)
+			}
+			if timing.BlackAndWhiteFlag != test.blackAndWhiteFlag || timing.BlackAndWhiteFlags != test.blackAndWhiteFlags {
+				t.Errorf("Black and white flag %v %d, expected %v %d", timing.BlackAndWhiteFlag, timing.BlackAndWhiteFlags,
+					test.blackAndWhiteFlag, test.blackAndWhiteFlags)
+			}
+		})
+	}
+}
+
+// A blue flag is shown for 30 seconds after the last one whatever is being sent
+func TestBlueFlagExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  connection.Payload
+		blueFlag bool
+	}{
+		{"heartbeat before expiry", message(connection.HeartbeatFile, sessionTime(39), `{"Utc":"2023-03-05T15:00:39Z"}`), true},
+		{"heartbeat after expiry", message(connection.HeartbeatFile, sessionTime(40), `{"Utc":"2023-03-05T15:00:40Z"}`), false},
+		{"race control after expiry", raceControlMessage(sessionTime(45), "Other", "RISK OF RAIN", ""), false},
+		{"timing after expiry", timingMessage(sessionTime(45), 1, `{"Position":"1"}`), false},
+		{"weather after expiry", message(connection.WeatherDataFile, sessionTime(45), `{"AirTemp":"20.0"}`), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+			session.process(driverListMessage(sessionTime(0), 1, 4), blueFlagMessage(10, 4), test.message)
+
+			timing := lastTiming(session, 4)
+			if timing.BlueFlag != test.blueFlag {
+				t.Errorf("Blue flag is %v, expected %v", timing.BlueFlag, test.blueFlag)
+			}
+			if timing.BlueFlags != 1 {
+				t.Errorf("Expected the blue flag to still be counted but got %d", timing.BlueFlags)
+			}
+		})
+	}
+}
+
+// A new blue flag starts the 30 seconds again
+func TestBlueFlagRenewed(t *testing.T) {
+	session := createTestSession(parser.Timing, Messages.RaceSession)
+	session.process(
+		driverListMessage(sessionTime(0), 1, 4),
+		blueFlagMessage(10, 4),
+		blueFlagMessage(30, 4),
+		message(connection.HeartbeatFile, sessionTime(45), `{"Utc":"2023-03-05T15:00:45Z"}`))
+
+	if !lastTiming(session, 4).BlueFlag {
+		t.Error("Expected the blue flag to still be shown")
+	}
+
+	session.process(message(connection.HeartbeatFile, sessionTime(60), `{"Utc":"2023-03-05T15:01:00Z"}`))
+
+	if lastTiming(session, 4).BlueFlag {
+		t.Error("Expected the blue flag to have expired")
+	}
+}
+
+func lastTiming(session *testSession, driver int) Messages.Timing {
+	for x := len(session.output.timing) - 1; x >= 0; x-- {
+		if session.output.timing[x].Number == driver {
+			return session.output.timing[x]
+		}
+	}
+	return Messages.Timing{}
+}
@@ -0,0 +1,44 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestDriverListOnlySentForDriverChanges(t *testing.T) {
+	session := createTestSession(parser.Drivers, Messages.RaceSession)
+
+	session.process(
+		driverListMessage(sessionTime(0), 1, 44),
+		// Position changes during the race
+		message(connection.DriverListFile, sessionTime(10), `{"1":{"Line":2},"44":{"Line":1}}`),
+		message(connection.DriverListFile, sessionTime(20), `{"44":{"TeamName":"Mercedes","TeamColour":"27F4D2"}}`))
+
+	drivers := session.output.drivers
+	if len(drivers) != 2 {
+		t.Fatalf("Expected 2 driver lists but got %d", len(drivers))
+	}
+
+	for _, driver := range drivers[1].Drivers {
+		if driver.Number == 44 && (driver.Team != "Mercedes" || driver.HexColor != "#27F4D2") {
+			t.Errorf("Expected the team to have been updated but got %v", driver)
+		}
+	}
+}
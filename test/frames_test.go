@@ -0,0 +1,295 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/flowControl"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const benchmarkCars = 20
+
+type benchmarkOutputs struct {
+	location        chan Messages.Location
+	telemetry       chan Messages.Telemetry
+	locationFrames  chan Messages.LocationFrame
+	telemetryFrames chan Messages.TelemetryFrame
+}
+
+func createBenchmarkFlow(ctx context.Context, wg *sync.WaitGroup, flowType flowControl.FlowType) (flowControl.Flow, benchmarkOutputs) {
+	outputs := benchmarkOutputs{
+		location:        make(chan Messages.Location, 1000),
+		telemetry:       make(chan Messages.Telemetry, 1000),
+		locationFrames:  make(chan Messages.LocationFrame, 100),
+		telemetryFrames: make(chan Messages.TelemetryFrame, 100),
+	}
+
+	flow := flowControl.CreateFlowControl(
+		ctx,
+		wg,
+		flowType,
+		make(chan Messages.Weather, 1),
+		make(chan Messages.RaceControlMessage, 1),
+		make(chan Messages.Timing, 1),
+		make(chan Messages.Event, 1),
+		outputs.telemetry,
+		outputs.location,
+		make(chan Messages.EventTime, 1),
+		make(chan Messages.Radio, 1),
+		make(chan Messages.Drivers, 1),
+		make(chan Messages.Lap, 1),
+		make(chan Messages.Classification, 1),
+		make(chan Messages.Incident, 1),
+		outputs.locationFrames,
+		outputs.telemetryFrames,
+		make(chan Messages.CarSample, 1),
+		make(chan Messages.Intervals, 1),
+		make(chan Messages.LapCorners, 1))
+
+	return flow, outputs
+}
+
+func benchmarkLocations(timestamp time.Time) []Messages.Location {
+	result := make([]Messages.Location, benchmarkCars)
+	for x := range result {
+		result[x] = Messages.Location{
+			Timestamp:    timestamp,
+			DriverNumber: x + 1,
+			X:            float64(x),
+			Y:            float64(x),
+			Status:       Messages.PositionOnTrack,
+		}
+	}
+	return result
+}
+
+func benchmarkTelemetry(timestamp time.Time) []Messages.Telemetry {
+	result := make([]Messages.Telemetry, benchmarkCars)
+	for x := range result {
+		result[x] = Messages.Telemetry{
+			Timestamp:    timestamp,
+			DriverNumber: x + 1,
+			RPM:          11000,
+			Speed:        300,
+			Gear:         8,
+			Throttle:     100,
+		}
+	}
+	return result
+}
+
+func BenchmarkLocationPerCar(b *testing.B) {
+	flow, outputs := createBenchmarkFlow(context.Background(), &sync.WaitGroup{}, flowControl.StraightThrough)
+	locations := benchmarkLocations(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		for x := 0; x < b.N*benchmarkCars; x++ {
+			<-outputs.location
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, location := range locations {
+			flow.AddLocation(location)
+		}
+	}
+	<-done
+}
+
+func BenchmarkLocationFrame(b *testing.B) {
+	flow, outputs := createBenchmarkFlow(context.Background(), &sync.WaitGroup{}, flowControl.StraightThrough)
+	locations := benchmarkLocations(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		for x := 0; x < b.N; x++ {
+			<-outputs.locationFrames
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		flow.AddLocationFrame(Messages.LocationFrame{Timestamp: locations[0].Timestamp, Locations: locations})
+	}
+	<-done
+}
+
+func BenchmarkTelemetryPerCar(b *testing.B) {
+	flow, outputs := createBenchmarkFlow(context.Background(), &sync.WaitGroup{}, flowControl.StraightThrough)
+	telemetry := benchmarkTelemetry(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		for x := 0; x < b.N*benchmarkCars; x++ {
+			<-outputs.telemetry
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, sample := range telemetry {
+			flow.AddTelemetry(sample)
+		}
+	}
+	<-done
+}
+
+func BenchmarkTelemetryFrame(b *testing.B) {
+	flow, outputs := createBenchmarkFlow(context.Background(), &sync.WaitGroup{}, flowControl.StraightThrough)
+	telemetry := benchmarkTelemetry(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		for x := 0; x < b.N; x++ {
+			<-outputs.telemetryFrames
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		flow.AddTelemetryFrame(Messages.TelemetryFrame{Timestamp: telemetry[0].Timestamp, Telemetry: telemetry})
+	}
+	<-done
+}
+
+// Start the realtime flow sending so the cost of queuing includes the contention with it
+func runRealtimeBenchmarkFlow() (flowControl.Flow, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	flow, _ := createBenchmarkFlow(ctx, wg, flowControl.Realtime)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		flow.Run()
+	}()
+
+	return flow, func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// Cost of queuing the data in the realtime flow, sending is limited by its ticker
+func BenchmarkRealtimeLocationPerCar(b *testing.B) {
+	flow, stop := runRealtimeBenchmarkFlow()
+	defer stop()
+	locations := benchmarkLocations(time.Now())
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, location := range locations {
+			flow.AddLocation(location)
+		}
+	}
+	b.StopTimer()
+}
+
+func BenchmarkRealtimeLocationFrame(b *testing.B) {
+	flow, stop := runRealtimeBenchmarkFlow()
+	defer stop()
+	locations := benchmarkLocations(time.Now())
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		flow.AddLocationFrame(Messages.LocationFrame{Timestamp: locations[0].Timestamp, Locations: locations})
+	}
+	b.StopTimer()
+}
+
+// The live feed sends the location and telemetry files as raw deflate data encoded as base64
+func compressedMessage(name string, timestamp time.Time, data string) connection.Payload {
+	var buf bytes.Buffer
+	writer, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	writer.Write([]byte(data))
+	writer.Close()
+
+	return message(name, timestamp, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+func benchmarkPositionMessage(timestamp time.Time) connection.Payload {
+	entries := make([]string, benchmarkCars)
+	for x := range entries {
+		entries[x] = fmt.Sprintf(`"%d":{"Status":"OnTrack","X":%d,"Y":%d,"Z":0}`, x+1, (x+1)*10, (x+1)*10)
+	}
+
+	return compressedMessage(connection.PositionFile, timestamp, fmt.Sprintf(
+		`{"Position":[{"Timestamp":"%s","Entries":{%s}}]}`,
+		timestamp.Format("2006-01-02T15:04:05.999Z"),
+		strings.Join(entries, ",")))
+}
+
+func benchmarkCarDataMessage(timestamp time.Time) connection.Payload {
+	cars := make([]string, benchmarkCars)
+	for x := range cars {
+		cars[x] = fmt.Sprintf(`"%d":{"Channels":{"0":11000,"2":300,"3":8,"4":100,"5":0,"45":12}}`, x+1)
+	}
+
+	return compressedMessage(connection.CarDataFile, timestamp, fmt.Sprintf(
+		`{"Entries":[{"Utc":"%s","Cars":{%s}}]}`,
+		timestamp.Format("2006-01-02T15:04:05.999Z"),
+		strings.Join(cars, ",")))
+}
+
+// Decompress, parse and group the messages into frames the same way a live session does
+func benchmarkParser(b *testing.B, requestedData parser.DataSource, msg connection.Payload) {
+	session := createTestSession(requestedData, Messages.RaceSession)
+
+	drivers := make([]int, benchmarkCars)
+	for x := range drivers {
+		drivers[x] = x + 1
+	}
+	session.parser.SelectTelemetrySources(drivers)
+
+	done := make(chan struct{})
+	go func() {
+		session.parser.Process()
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		session.incoming <- msg
+	}
+	session.incoming <- connection.Payload{Name: connection.EndOfDataFile}
+	<-done
+	b.StopTimer()
+}
+
+func BenchmarkParseLocationFrames(b *testing.B) {
+	benchmarkParser(b, parser.LocationFrames, benchmarkPositionMessage(sessionTime(1)))
+}
+
+func BenchmarkParseTelemetryFrames(b *testing.B) {
+	benchmarkParser(b, parser.TelemetryFrames, benchmarkCarDataMessage(sessionTime(1)))
+}
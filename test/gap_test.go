@@ -0,0 +1,64 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+	"time"
+)
+
+func TestGapToLeader(t *testing.T) {
+	tests := []struct {
+		gap      string
+		expected Messages.Gap
+	}{
+		{"+12.345", Messages.Gap{Type: Messages.TimeGap, Time: 12345 * time.Millisecond}},
+		{"+1:02.345", Messages.Gap{Type: Messages.TimeGap, Time: 62345 * time.Millisecond}},
+		{"+1 LAP", Messages.Gap{Type: Messages.LapsGap, Laps: 1}},
+		{"+2 LAPS", Messages.Gap{Type: Messages.LapsGap, Laps: 2}},
+		{"3L", Messages.Gap{Type: Messages.LapsGap, Laps: 3}},
+		{"LAP 45", Messages.Gap{Type: Messages.LeaderLapGap, Laps: 45}},
+		// An empty value clears the previous gap
+		{"", Messages.Gap{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.gap, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+
+			session.process(
+				driverListMessage(sessionTime(0), 1),
+				timingMessage(sessionTime(1), 1, `{"GapToLeader":"+5.000"}`),
+				timingMessage(sessionTime(2), 1, fmt.Sprintf(`{"GapToLeader":"%s"}`, test.gap)))
+
+			timing := session.output.timing
+			if len(timing) != 2 {
+				t.Fatalf("Expected 2 timing updates but got %d", len(timing))
+			}
+
+			if timing[1].GapToLeader != test.expected {
+				t.Errorf("Expected %v but got %v", test.expected, timing[1].GapToLeader)
+			}
+
+			if session.log.Len() > 0 {
+				t.Errorf("Unexpected errors: %s", session.log.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"context"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/f1log"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Asset store that holds on to a headshot request until it is released
+type blockingAssets struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingAssets) TeamRadio(file string) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *blockingAssets) DriverHeadshot(url string) ([]byte, error) {
+	close(b.started)
+	<-b.release
+	return []byte("image"), nil
+}
+
+// Stopping the session has to wait for a headshot that is still being fetched
+func TestHeadshotFetchTracked(t *testing.T) {
+	assets := &blockingAssets{started: make(chan struct{}), release: make(chan struct{})}
+	incoming := make(chan connection.Payload, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	p := parser.Create(
+		ctx,
+		wg,
+		parser.Drivers|parser.DriverHeadshots,
+		incoming,
+		&recordingFlow{},
+		assets,
+		Messages.RaceSession,
+		f1log.CreateLog(),
+		time.UTC)
+
+	incoming <- message(connection.DriverListFile, sessionTime(0),
+		`{"1":{"RacingNumber":"1","Tla":"D01","Line":1,"HeadshotUrl":"http://localhost/1.png"}}`)
+	incoming <- connection.Payload{Name: connection.EndOfDataFile}
+	p.Process()
+
+	<-assets.started
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stopped before the headshot fetch finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(assets.release)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Headshot fetch didn't finish after being released")
+	}
+}
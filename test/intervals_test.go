@@ -0,0 +1,209 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A square track 1km along each side so a lap is 4km
+const intervalSide = 10000.0
+const intervalLapLength = 4000.0
+
+// Where a car is after covering the distance in metres, anti-clockwise from (0, 0)
+func intervalPoint(distance float64) trackMap.Point {
+	fraction := distance / intervalLapLength
+	fraction = fraction - math.Floor(fraction)
+	side := int(fraction * 4)
+	along := (fraction*4 - float64(side)) * intervalSide
+
+	switch side {
+	case 0:
+		return trackMap.Point{X: along, Y: 0}
+	case 1:
+		return trackMap.Point{X: intervalSide, Y: along}
+	case 2:
+		return trackMap.Point{X: intervalSide - along, Y: intervalSide}
+	default:
+		return trackMap.Point{X: 0, Y: intervalSide - along}
+	}
+}
+
+// The pit lane cuts the corner at the line from half way down the last side to half way along the first side
+var intervalPitLane = []trackMap.Point{{X: 0, Y: 5000}, {X: 2500, Y: 2500}, {X: 5000, Y: 0}}
+
+func createIntervalSession() *testSession {
+	session := createTestSession(parser.Intervals|parser.Location, Messages.RaceSession)
+	session.parser.UseTrackMap(trackMap.TrackMap{
+		Outline: []trackMap.Point{{X: 0, Y: 0}, {X: intervalSide, Y: 0}, {X: intervalSide, Y: intervalSide}, {X: 0, Y: intervalSide}},
+		PitLane: intervalPitLane,
+	})
+	session.process(driverListMessage(sessionTime(0), 1, 2))
+	return session
+}
+
+// Locations for cars at the same time, a point with (0, 0) is sent as no fix
+func intervalLocations(seconds float64, points map[int]trackMap.Point) connection.Payload {
+	timestamp := sessionTime(seconds)
+	entries := make([]string, 0, len(points))
+	for driver, point := range points {
+		entries = append(entries, fmt.Sprintf(`"%d":{"Status":"OnTrack","X":%f,"Y":%f,"Z":0}`, driver, point.X, point.Y))
+	}
+
+	return compressedMessage(connection.PositionFile, timestamp, fmt.Sprintf(
+		`{"Position":[{"Timestamp":"%s","Entries":{%s}}]}`,
+		timestamp.Format("2006-01-02T15:04:05.999Z"),
+		strings.Join(entries, ",")))
+}
+
+// Both cars driving at 50m/s starting from the distances
+func drive(from float64, to float64, start map[int]float64) []connection.Payload {
+	var result []connection.Payload
+	for seconds := from; seconds <= to; seconds += 0.5 {
+		points := make(map[int]trackMap.Point)
+		for driver, distance := range start {
+			points[driver] = intervalPoint(distance + (seconds-from)*50)
+		}
+		result = append(result, intervalLocations(seconds, points))
+	}
+	return result
+}
+
+func lastInterval(t *testing.T, session *testSession, driver int) Messages.CarInterval {
+	if len(session.output.intervals) == 0 {
+		t.Fatalf("No intervals sent, log: %s", session.log.String())
+	}
+
+	for _, car := range session.output.intervals[len(session.output.intervals)-1].Cars {
+		if car.DriverNumber == driver {
+			return car
+		}
+	}
+
+	t.Fatalf("No interval for driver %d", driver)
+	return Messages.CarInterval{}
+}
+
+func TestIntervals(t *testing.T) {
+	tests := []struct {
+		name      string
+		laps      map[int]int
+		start     map[int]float64
+		position  int
+		gapAhead  time.Duration
+		gapLeader time.Duration
+		lapsDown  int
+	}{
+		{"Close behind", map[int]int{1: 2, 2: 2}, map[int]float64{1: 1100, 2: 1000}, 2, 2 * time.Second, 2 * time.Second, 0},
+		{"Ahead", map[int]int{1: 2, 2: 2}, map[int]float64{1: 1000, 2: 1500}, 1, 0, 0, 0},
+		{"Lapped", map[int]int{1: 3, 2: 2}, map[int]float64{1: 1100, 2: 1000}, 2, 0, 0, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createIntervalSession()
+			session.process(
+				timingMessage(sessionTime(1), 1, fmt.Sprintf(`{"NumberOfLaps":%d}`, test.laps[1])),
+				timingMessage(sessionTime(1), 2, fmt.Sprintf(`{"NumberOfLaps":%d}`, test.laps[2])))
+			session.process(drive(2, 12, test.start)...)
+
+			car := lastInterval(t, session, 2)
+			if car.Position != test.position {
+				t.Errorf("Position is %d, expected %d", car.Position, test.position)
+			}
+			if car.Laps != test.laps[2] {
+				t.Errorf("Laps is %d, expected %d", car.Laps, test.laps[2])
+			}
+			if (car.GapToCarAhead - test.gapAhead).Abs() > 10*time.Millisecond {
+				t.Errorf("Gap to car ahead is %s, expected %s", car.GapToCarAhead, test.gapAhead)
+			}
+			if (car.GapToLeader - test.gapLeader).Abs() > 10*time.Millisecond {
+				t.Errorf("Gap to leader is %s, expected %s", car.GapToLeader, test.gapLeader)
+			}
+			if car.LapsDown != test.lapsDown {
+				t.Errorf("Laps down is %d, expected %d", car.LapsDown, test.lapsDown)
+			}
+		})
+	}
+}
+
+// The timing changes lap after the car has crossed the line so losing the location just after the line
+// mustn't lose a lap
+func TestIntervalsNoFixAfterTheLine(t *testing.T) {
+	session := createIntervalSession()
+	session.process(timingMessage(sessionTime(1), 1, `{"NumberOfLaps":2}`))
+
+	// Crosses the line at 12 seconds
+	session.process(drive(2, 13, map[int]float64{1: 3500})...)
+	session.process(intervalLocations(13.5, map[int]trackMap.Point{1: {X: 0, Y: 0}}))
+	session.process(drive(14, 15, map[int]float64{1: 100})...)
+
+	car := lastInterval(t, session, 1)
+	if car.Laps != 3 || math.Abs(car.Distance-(3*intervalLapLength+150)) > 0.1 {
+		t.Errorf("Car is on lap %d at %fm, expected lap 3 at %fm", car.Laps, car.Distance, 3*intervalLapLength+150)
+	}
+
+	// The timing catching up doesn't change anything
+	session.process(timingMessage(sessionTime(15.5), 1, `{"NumberOfLaps":3}`))
+	session.process(drive(16, 28, map[int]float64{1: 200})...)
+
+	car = lastInterval(t, session, 1)
+	if car.Laps != 3 || math.Abs(car.Distance-(3*intervalLapLength+800)) > 0.1 {
+		t.Errorf("Car is on lap %d at %fm, expected lap 3 at %fm", car.Laps, car.Distance, 3*intervalLapLength+800)
+	}
+}
+
+// A car first seen before the line on the grid has its laps corrected from the timing away from the line
+func TestIntervalsStartNearTheLine(t *testing.T) {
+	session := createIntervalSession()
+	session.process(timingMessage(sessionTime(1), 1, `{"NumberOfLaps":0}`))
+	session.process(drive(2, 17, map[int]float64{1: 3800})...)
+
+	car := lastInterval(t, session, 1)
+	if car.Laps != 0 || math.Abs(car.Distance-550) > 0.1 {
+		t.Errorf("Car is on lap %d at %fm, expected lap 0 at 550m", car.Laps, car.Distance)
+	}
+}
+
+// Cars in the pit lane are placed by how far along the pit lane they are
+func TestIntervalsPitLane(t *testing.T) {
+	session := createIntervalSession()
+	session.process(timingMessage(sessionTime(1), 1, `{"NumberOfLaps":2,"InPit":true}`))
+	session.process(intervalLocations(2, map[int]trackMap.Point{1: intervalPitLane[0]}))
+	session.process(intervalLocations(3, map[int]trackMap.Point{1: intervalPitLane[1]}))
+
+	// Half way along the pit lane is the line, the nearest point on the track is 250m away on either side
+	location := session.output.locations[len(session.output.locations)-1]
+	if distance := math.Min(location.LapDistance, intervalLapLength-location.LapDistance); distance > 0.1 {
+		t.Errorf("Lap distance in the pit lane is %fm, expected the line", location.LapDistance)
+	}
+
+	car := lastInterval(t, session, 1)
+	if !car.InPit {
+		t.Error("Car is not in the pit lane")
+	}
+	if car.Laps != 3 || math.Abs(car.Distance-3*intervalLapLength) > 0.1 {
+		t.Errorf("Car is on lap %d at %fm, expected lap 3 at %fm", car.Laps, car.Distance, 3*intervalLapLength)
+	}
+}
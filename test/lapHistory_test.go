@@ -0,0 +1,152 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestLapCompletion(t *testing.T) {
+	session := createTestSession(parser.Laps, Messages.RaceSession)
+
+	session.process(
+		driverListMessage(sessionTime(0), 1),
+		// Joining part way through a lap so we haven't seen all of it
+		timingMessage(sessionTime(1), 1, `{"NumberOfLaps":5}`),
+		timingMessage(sessionTime(90), 1, `{"NumberOfLaps":6,"LastLapTime":{"Value":"1:30.123"}}`),
+		timingMessage(sessionTime(180), 1, `{"NumberOfLaps":7}`),
+		// Lap times can arrive after the lap count has changed
+		timingMessage(sessionTime(181), 1, `{"LastLapTime":{"Value":"1:29.456"}}`))
+
+	// The late lap time sends lap 7 again
+	laps := session.output.laps
+	if len(laps) != 3 {
+		t.Fatalf("Expected 3 laps but got %d: %v", len(laps), laps)
+	}
+
+	if laps[0].Lap != 6 || laps[1].Lap != 7 || laps[2].Lap != 7 {
+		t.Errorf("Expected laps 6, 7 and 7 but got %d, %d and %d", laps[0].Lap, laps[1].Lap, laps[2].Lap)
+	}
+	if laps[1].LapTime != 0 || laps[2].LapTime.String() != "1m29.456s" {
+		t.Errorf("Expected lap 7 without a time and then with 1m29.456s but got %v and %v", laps[1].LapTime, laps[2].LapTime)
+	}
+
+	history := session.parser.LapHistory(1)
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 laps in the history but got %d", len(history))
+	}
+
+	if history[0].LapTime.String() != "1m30.123s" {
+		t.Errorf("Expected a lap time of 1m30.123s for lap 6 but got %v", history[0].LapTime)
+	}
+	if history[1].LapTime.String() != "1m29.456s" {
+		t.Errorf("Expected the late lap time of 1m29.456s for lap 7 but got %v", history[1].LapTime)
+	}
+}
+
+// Laps from the previous session are forgotten when the session changes
+func TestLapHistoryNewSession(t *testing.T) {
+	session := createTestSession(parser.Laps, Messages.QualifyingSession)
+
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(0), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Qualifying"}`),
+		driverListMessage(sessionTime(0), 1),
+		timingMessage(sessionTime(1), 1, `{"NumberOfLaps":5}`),
+		timingMessage(sessionTime(90), 1, `{"NumberOfLaps":6,"LastLapTime":{"Value":"1:30.123"}}`))
+
+	if len(session.parser.LapHistory(1)) != 1 {
+		t.Fatalf("Expected 1 lap in the history but got %d", len(session.parser.LapHistory(1)))
+	}
+
+	session.process(message(connection.SessionInfoFile, sessionTime(3600), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race"}`))
+
+	if len(session.parser.LapHistory(1)) != 0 {
+		t.Errorf("Expected no laps after the session changed but got %d", len(session.parser.LapHistory(1)))
+	}
+}
+
+func TestLapWorstSafetyCar(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		expected Messages.TrackState
+	}{
+		{"clear", []string{}, Messages.Clear},
+		{"vsc", []string{"VIRTUAL SAFETY CAR DEPLOYED"}, Messages.VirtualSafetyCar},
+		{"vsc ending", []string{"VIRTUAL SAFETY CAR DEPLOYED", "VIRTUAL SAFETY CAR ENDING"}, Messages.VirtualSafetyCar},
+		{"vsc then safety car", []string{"VIRTUAL SAFETY CAR DEPLOYED", "SAFETY CAR DEPLOYED"}, Messages.SafetyCar},
+		{"safety car then vsc", []string{"SAFETY CAR DEPLOYED", "VIRTUAL SAFETY CAR DEPLOYED"}, Messages.SafetyCar},
+		{"safety car ending", []string{"SAFETY CAR IN THIS LAP"}, Messages.SafetyCarEnding},
+		{"safety car ending beats vsc", []string{"SAFETY CAR IN THIS LAP", "VIRTUAL SAFETY CAR DEPLOYED"}, Messages.SafetyCarEnding},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Laps, Messages.RaceSession)
+
+			messages := []connection.Payload{
+				driverListMessage(sessionTime(0), 1),
+				timingMessage(sessionTime(1), 1, `{"NumberOfLaps":1}`),
+			}
+			for x, text := range test.messages {
+				messages = append(messages, raceControlMessage(sessionTime(float64(10+x)), "SafetyCar", text, ""))
+			}
+			// Clear before the lap ends so only the worst state seen during the lap is kept
+			messages = append(messages,
+				raceControlMessage(sessionTime(50), "Flag", "TRACK CLEAR", `"Flag":"CLEAR","Scope":"Track"`),
+				timingMessage(sessionTime(90), 1, `{"NumberOfLaps":2}`))
+
+			session.process(messages...)
+
+			laps := session.output.laps
+			if len(laps) != 2 {
+				t.Fatalf("Expected 2 laps but got %d", len(laps))
+			}
+
+			if laps[1].SafetyCar != test.expected {
+				t.Errorf("Expected %v but got %v", test.expected, laps[1].SafetyCar)
+			}
+		})
+	}
+}
+
+func TestLapWorstFlag(t *testing.T) {
+	session := createTestSession(parser.Laps, Messages.RaceSession)
+
+	session.process(
+		driverListMessage(sessionTime(0), 1),
+		timingMessage(sessionTime(1), 1, `{"NumberOfLaps":1}`),
+		raceControlMessage(sessionTime(10), "Flag", "DOUBLE YELLOW", `"Flag":"DOUBLE YELLOW","Scope":"Track"`),
+		raceControlMessage(sessionTime(20), "Flag", "YELLOW", `"Flag":"YELLOW","Scope":"Track"`),
+		raceControlMessage(sessionTime(30), "Flag", "TRACK CLEAR", `"Flag":"CLEAR","Scope":"Track"`),
+		timingMessage(sessionTime(90), 1, `{"NumberOfLaps":2}`),
+		timingMessage(sessionTime(180), 1, `{"NumberOfLaps":3}`))
+
+	laps := session.output.laps
+	if len(laps) != 3 {
+		t.Fatalf("Expected 3 laps but got %d", len(laps))
+	}
+
+	if laps[1].TrackStatus != Messages.DoubleYellowFlag {
+		t.Errorf("Expected a double yellow for lap 2 but got %v", laps[1].TrackStatus)
+	}
+	if laps[2].TrackStatus != Messages.GreenFlag {
+		t.Errorf("Expected green for lap 3 but got %v", laps[2].TrackStatus)
+	}
+}
@@ -0,0 +1,91 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+	"testing"
+)
+
+func carDataMessage(seconds float64, driver int, channels string) connection.Payload {
+	timestamp := sessionTime(seconds)
+	return compressedMessage(connection.CarDataFile, timestamp, fmt.Sprintf(
+		`{"Entries":[{"Utc":"%s","Cars":{"%d":{"Channels":{%s}}}}]}`,
+		timestamp.Format("2006-01-02T15:04:05.999Z"), driver, channels))
+}
+
+// A lap of the 4km square at 180km/h from 3900m on lap 1, crossing the line at 4 and 84 seconds. The telemetry is a
+// quarter of a second after each location and there are no locations between 40 and 44 seconds.
+func lapTelemetryMessages() []connection.Payload {
+	result := []connection.Payload{timingMessage(sessionTime(1), 1, `{"NumberOfLaps":1}`)}
+
+	for seconds := 2.0; seconds <= 90; seconds += 0.5 {
+		if seconds < 40 || seconds > 44 {
+			result = append(result, intervalLocations(seconds, map[int]trackMap.Point{1: intervalPoint(3900 + (seconds-2)*50)}))
+		}
+		result = append(result, carDataMessage(seconds+0.25, 1, `"0":11000,"2":180,"3":6,"4":100,"5":0`))
+
+		switch seconds {
+		case 5:
+			result = append(result, timingMessage(sessionTime(seconds), 1, `{"NumberOfLaps":2}`))
+		case 85:
+			result = append(result, timingMessage(sessionTime(seconds), 1, `{"NumberOfLaps":3}`))
+		}
+	}
+
+	return result
+}
+
+// Every car is buffered whatever telemetry has been selected and samples with no lap distance are left out
+func TestLapTelemetry(t *testing.T) {
+	session := createTestSession(parser.LapTelemetry, Messages.RaceSession)
+	session.parser.UseTrackMap(trackMap.TrackMap{
+		Outline: []trackMap.Point{{X: 0, Y: 0}, {X: intervalSide, Y: 0}, {X: intervalSide, Y: intervalSide}, {X: 0, Y: intervalSide}},
+	})
+	session.process(driverListMessage(sessionTime(0), 1))
+	session.process(lapTelemetryMessages()...)
+
+	lap := session.parser.LapTelemetry(1, 3)
+	if len(lap.Samples) == 0 {
+		t.Fatalf("No telemetry for the lap, log: %s", session.log.String())
+	}
+
+	// 80 seconds at 2 samples a second less the gap without locations
+	if len(lap.Samples) < 140 || len(lap.Samples) > 160 {
+		t.Errorf("Lap has %d samples", len(lap.Samples))
+	}
+
+	first := lap.Samples[0]
+	last := lap.Samples[len(lap.Samples)-1]
+	if first.Distance > 50 || last.Distance < intervalLapLength-50 {
+		t.Errorf("Lap goes from %fm to %fm", first.Distance, last.Distance)
+	}
+
+	for x := 1; x < len(lap.Samples); x++ {
+		if lap.Samples[x].Distance <= lap.Samples[x-1].Distance {
+			t.Fatalf("Sample %d at %fm is not after the previous sample at %fm",
+				x, lap.Samples[x].Distance, lap.Samples[x-1].Distance)
+		}
+	}
+
+	if _, err := session.parser.CompareLaps(1, 3, 1, 3, 10); err != nil {
+		t.Errorf("Comparing laps: %v", err)
+	}
+}
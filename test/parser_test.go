@@ -0,0 +1,173 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/f1log"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"strings"
+	"sync"
+	"time"
+)
+
+var testSessionStart = time.Date(2023, 3, 5, 15, 0, 0, 0, time.UTC)
+
+// Records everything the parser sends so the tests can check it
+type recordingFlow struct {
+	dummyFlowControl
+
+	timing    []Messages.Timing
+	laps      []Messages.Lap
+	penalties []Messages.Incident
+	drivers   []Messages.Drivers
+	events    []Messages.Event
+	samples   []Messages.CarSample
+	intervals []Messages.Intervals
+	locations []Messages.Location
+	telemetry []Messages.Telemetry
+
+	raceControl     []Messages.RaceControlMessage
+	classifications []Messages.Classification
+}
+
+func (r *recordingFlow) AddTiming(timing Messages.Timing) {
+	r.timing = append(r.timing, timing)
+}
+
+func (r *recordingFlow) AddLap(lap Messages.Lap) {
+	r.laps = append(r.laps, lap)
+}
+
+func (r *recordingFlow) AddPenalty(penalty Messages.Incident) {
+	r.penalties = append(r.penalties, penalty)
+}
+
+func (r *recordingFlow) AddDrivers(drivers Messages.Drivers) {
+	r.drivers = append(r.drivers, drivers)
+}
+
+func (r *recordingFlow) AddEvent(event Messages.Event) {
+	r.events = append(r.events, event)
+}
+
+func (r *recordingFlow) AddCarSample(sample Messages.CarSample) {
+	r.samples = append(r.samples, sample)
+}
+
+func (r *recordingFlow) AddIntervals(intervals Messages.Intervals) {
+	r.intervals = append(r.intervals, intervals)
+}
+
+func (r *recordingFlow) AddLocation(location Messages.Location) {
+	r.locations = append(r.locations, location)
+}
+
+func (r *recordingFlow) AddTelemetry(telemetry Messages.Telemetry) {
+	r.telemetry = append(r.telemetry, telemetry)
+}
+
+func (r *recordingFlow) AddRaceControlMessage(message Messages.RaceControlMessage) {
+	r.raceControl = append(r.raceControl, message)
+}
+
+func (r *recordingFlow) AddClassification(classification Messages.Classification) {
+	r.classifications = append(r.classifications, classification)
+}
+
+// A parser fed from messages built by the tests instead of a connection
+type testSession struct {
+	parser   *parser.Parser
+	output   *recordingFlow
+	log      *bytes.Buffer
+	incoming chan connection.Payload
+}
+
+func createTestSession(requestedData parser.DataSource, session Messages.SessionType) *testSession {
+	result := &testSession{
+		output:   &recordingFlow{},
+		log:      &bytes.Buffer{},
+		incoming: make(chan connection.Payload, 1000),
+	}
+
+	log := f1log.CreateLog()
+	log.SetLogOutput(result.log)
+
+	result.parser = parser.Create(
+		context.Background(),
+		&sync.WaitGroup{},
+		requestedData,
+		result.incoming,
+		result.output,
+		nil,
+		session,
+		log,
+		time.UTC)
+
+	return result
+}
+
+// Parse the messages and wait until they have all been handled
+func (t *testSession) process(messages ...connection.Payload) {
+	for _, msg := range messages {
+		t.incoming <- msg
+	}
+	t.incoming <- connection.Payload{Name: connection.EndOfDataFile}
+
+	t.parser.Process()
+}
+
+func message(name string, timestamp time.Time, data string) connection.Payload {
+	return connection.Payload{
+		Name:      name,
+		Data:      []byte(data),
+		Timestamp: timestamp.Format("2006-01-02T15:04:05.999Z"),
+	}
+}
+
+func sessionTime(seconds float64) time.Time {
+	return testSessionStart.Add(time.Duration(seconds * float64(time.Second)))
+}
+
+func driverListMessage(timestamp time.Time, drivers ...int) connection.Payload {
+	entries := make([]string, len(drivers))
+	for x, driver := range drivers {
+		entries[x] = fmt.Sprintf(`"%d":{"RacingNumber":"%d","Tla":"D%02d","Line":%d}`, driver, driver, driver, x+1)
+	}
+
+	return message(connection.DriverListFile, timestamp, "{"+strings.Join(entries, ",")+"}")
+}
+
+func timingMessage(timestamp time.Time, driver int, data string) connection.Payload {
+	return message(connection.TimingDataFile, timestamp, fmt.Sprintf(`{"Lines":{"%d":%s}}`, driver, data))
+}
+
+func raceControlMessage(timestamp time.Time, category string, text string, extra string) connection.Payload {
+	if len(extra) > 0 {
+		extra = "," + extra
+	}
+
+	return message(connection.RaceControlMessagesFile, timestamp, fmt.Sprintf(
+		`{"Messages":[{"Utc":"%s","Category":"%s","Message":"%s"%s}]}`,
+		timestamp.Format("2006-01-02T15:04:05"),
+		category,
+		text,
+		extra))
+}
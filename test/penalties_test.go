@@ -0,0 +1,201 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"reflect"
+	"testing"
+)
+
+func TestStewardsMessages(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected *Messages.Incident
+	}{
+		{
+			"FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 44 (HAM) - CAUSING A COLLISION",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.TimePenalty, Penalised: 44, Seconds: 5,
+				Cars: []int{44}, Reason: "CAUSING A COLLISION"},
+		},
+		{
+			"FIA STEWARDS: 10 SECOND STOP/GO PENALTY FOR CAR 16 (LEC) - SPEEDING IN THE PIT LANE",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.StopGoPenalty, Penalised: 16, Seconds: 10,
+				Cars: []int{16}, Reason: "SPEEDING IN THE PIT LANE"},
+		},
+		{
+			"FIA STEWARDS: DRIVE THROUGH PENALTY FOR CAR 1 (VER) - FALSE START",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.DriveThroughPenalty, Penalised: 1,
+				Cars: []int{1}, Reason: "FALSE START"},
+		},
+		{
+			"FIA STEWARDS: 3 PLACE GRID PENALTY FOR CAR 4 (NOR) - IMPEDING",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.GridPenalty, Penalised: 4, GridPlaces: 3,
+				Cars: []int{4}, Reason: "IMPEDING"},
+		},
+		{
+			"FIA STEWARDS: REPRIMAND FOR CAR 63 (RUS) - UNSAFE RELEASE",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.Reprimand, Penalised: 63,
+				Cars: []int{63}, Reason: "UNSAFE RELEASE"},
+		},
+		{
+			"FIA STEWARDS: PENALTY SERVED - 5 SECOND TIME PENALTY FOR CAR 44 (HAM) - CAUSING A COLLISION",
+			&Messages.Incident{State: Messages.IncidentServed, Penalty: Messages.TimePenalty, Penalised: 44, Seconds: 5,
+				Cars: []int{44}},
+		},
+		{
+			"FIA STEWARDS: TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER) REVIEWED NO FURTHER INVESTIGATION",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.NoFurtherAction,
+				Cars: []int{44, 1}, Description: "TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER)"},
+		},
+		{
+			"FIA STEWARDS: NO FURTHER ACTION - TURN 4 INCIDENT INVOLVING CAR 18 (STR) - LEAVING THE TRACK",
+			&Messages.Incident{State: Messages.IncidentDecided, Penalty: Messages.NoFurtherAction,
+				Cars: []int{18}, Description: "TURN 4 INCIDENT INVOLVING CAR 18 (STR)", Reason: "LEAVING THE TRACK"},
+		},
+		{
+			"FIA STEWARDS: TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER) UNDER INVESTIGATION - CAUSING A COLLISION",
+			&Messages.Incident{State: Messages.IncidentUnderInvestigation,
+				Cars: []int{44, 1}, Description: "TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER)", Reason: "CAUSING A COLLISION"},
+		},
+		{
+			"FIA STEWARDS: CAR 10 (GAS) WILL BE INVESTIGATED AFTER THE RACE - UNSAFE RELEASE",
+			&Messages.Incident{State: Messages.IncidentUnderInvestigation,
+				Cars: []int{10}, Description: "CAR 10 (GAS)", Reason: "UNSAFE RELEASE"},
+		},
+		{
+			"FIA STEWARDS: TURN 3 INCIDENT INVOLVING CAR 22 (TSU) NOTED - FORCING ANOTHER DRIVER OFF THE TRACK",
+			&Messages.Incident{State: Messages.IncidentNoted,
+				Cars: []int{22}, Description: "TURN 3 INCIDENT INVOLVING CAR 22 (TSU)", Reason: "FORCING ANOTHER DRIVER OFF THE TRACK"},
+		},
+		{
+			"CAR 44 (HAM) TIME 1:32.456 DELETED - TRACK LIMITS AT TURN 4 LAP 12 15:04:05",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.msg, func(t *testing.T) {
+			session := createTestSession(parser.Penalties, Messages.RaceSession)
+
+			session.process(
+				driverListMessage(sessionTime(0), 1, 4, 10, 16, 18, 22, 44, 63),
+				raceControlMessage(sessionTime(10), "Other", test.msg, ""))
+
+			penalties := session.output.penalties
+			if test.expected == nil {
+				if len(penalties) != 0 {
+					t.Errorf("Expected no incidents but got %v", penalties)
+				}
+				return
+			}
+
+			if len(penalties) != 1 {
+				t.Fatalf("Expected 1 incident but got %d", len(penalties))
+			}
+
+			actual := penalties[0]
+			expected := *test.expected
+			if actual.State != expected.State ||
+				actual.Penalty != expected.Penalty ||
+				actual.Penalised != expected.Penalised ||
+				actual.Seconds != expected.Seconds ||
+				actual.GridPlaces != expected.GridPlaces ||
+				!reflect.DeepEqual(actual.Cars, expected.Cars) ||
+				(len(expected.Description) > 0 && actual.Description != expected.Description) ||
+				actual.Reason != expected.Reason {
+				t.Errorf("Expected %+v but got %+v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestStewardsIncidentProgress(t *testing.T) {
+	session := createTestSession(parser.Penalties, Messages.RaceSession)
+
+	session.process(
+		driverListMessage(sessionTime(0), 1, 44),
+		raceControlMessage(sessionTime(10), "Other",
+			"FIA STEWARDS: TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER) NOTED - CAUSING A COLLISION", ""),
+		raceControlMessage(sessionTime(20), "Other",
+			"FIA STEWARDS: TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER) UNDER INVESTIGATION - CAUSING A COLLISION", ""),
+		raceControlMessage(sessionTime(30), "Other",
+			"FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 44 (HAM) - CAUSING A COLLISION", ""),
+		raceControlMessage(sessionTime(40), "Other",
+			"FIA STEWARDS: PENALTY SERVED - 5 SECOND TIME PENALTY FOR CAR 44 (HAM) - CAUSING A COLLISION", ""))
+
+	penalties := session.output.penalties
+	if len(penalties) != 4 {
+		t.Fatalf("Expected 4 updates but got %d", len(penalties))
+	}
+
+	for _, penalty := range penalties {
+		if penalty.Id != penalties[0].Id {
+			t.Errorf("Expected every update to be for incident %d but got %d", penalties[0].Id, penalty.Id)
+		}
+	}
+
+	if len(penalties[3].Messages) != 4 {
+		t.Errorf("Expected the incident to have all 4 messages but got %v", penalties[3].Messages)
+	}
+
+	summary := session.parser.PenaltySummary(44)
+	if summary.ServedTimePenaltySeconds != 5 || summary.TimePenaltySeconds != 0 {
+		t.Errorf("Expected 5 seconds served but got %+v", summary)
+	}
+
+	summary = session.parser.PenaltySummary(1)
+	if summary.ServedTimePenaltySeconds != 0 || len(summary.Incidents) != 1 {
+		t.Errorf("Expected car 1 to be involved without a penalty but got %+v", summary)
+	}
+}
+
+// A decision in a new session mustn't be matched to an incident from the previous one
+func TestStewardsNewSession(t *testing.T) {
+	session := createTestSession(parser.Penalties|parser.Timing|parser.Event, Messages.RaceSession)
+
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(0), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Qualifying"}`),
+		driverListMessage(sessionTime(0), 1, 44),
+		raceControlMessage(sessionTime(10), "Other",
+			"FIA STEWARDS: TURN 1 INCIDENT INVOLVING CARS 44 (HAM) AND 1 (VER) UNDER INVESTIGATION - CAUSING A COLLISION", ""),
+		raceControlMessage(sessionTime(20), "Other", "FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 1 (VER) - SPEEDING IN THE PIT LANE", ""),
+		message(connection.SessionInfoFile, sessionTime(3600), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race"}`))
+
+	summary := session.parser.PenaltySummary(44)
+	if len(summary.Incidents) != 0 {
+		t.Errorf("Expected the incidents to be cleared but got %+v", summary)
+	}
+
+	timing := session.output.timing[len(session.output.timing)-1]
+	if timing.TimePenaltySeconds != 0 {
+		t.Errorf("Expected the time penalty to be cleared but got %d", timing.TimePenaltySeconds)
+	}
+
+	session.output.penalties = nil
+	session.process(raceControlMessage(sessionTime(3700), "Other",
+		"FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 44 (HAM) - CAUSING A COLLISION", ""))
+
+	penalties := session.output.penalties
+	if len(penalties) != 1 {
+		t.Fatalf("Expected 1 update but got %d", len(penalties))
+	}
+	if penalties[0].Id != 1 || len(penalties[0].Messages) != 1 {
+		t.Errorf("Expected a new incident but got %+v", penalties[0])
+	}
+}
@@ -0,0 +1,108 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"strings"
+	"testing"
+)
+
+// Entries are written as given so a test can leave out the status or send a location of (0, 0)
+func positionMessage(seconds float64, entries ...string) connection.Payload {
+	timestamp := sessionTime(seconds)
+	return compressedMessage(connection.PositionFile, timestamp, fmt.Sprintf(
+		`{"Position":[{"Timestamp":"%s","Entries":{%s}}]}`,
+		timestamp.Format("2006-01-02T15:04:05.999Z"),
+		strings.Join(entries, ",")))
+}
+
+func locationStatuses(locations []Messages.Location, driver int) []Messages.PositionStatus {
+	result := make([]Messages.PositionStatus, 0)
+	for _, location := range locations {
+		if location.DriverNumber == driver {
+			result = append(result, location.Status)
+		}
+	}
+	return result
+}
+
+func TestPositionStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []connection.Payload
+		expected []Messages.PositionStatus
+	}{
+		{"on track", []connection.Payload{positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack}},
+		{"no status", []connection.Payload{positionMessage(10, `"1":{"X":100,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack}},
+		{"off track", []connection.Payload{positionMessage(10, `"1":{"Status":"OffTrack","X":100,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOffTrack}},
+		{"unknown status", []connection.Payload{positionMessage(10, `"1":{"Status":"Sideways","X":100,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionUnknown}},
+		{"lost location", []connection.Payload{
+			positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`),
+			positionMessage(11, `"1":{"Status":"OnTrack","X":0,"Y":0,"Z":0}`),
+			positionMessage(12, `"1":{"Status":"OnTrack","X":0,"Y":0,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack, Messages.PositionNoFix}},
+		{"never had a location", []connection.Payload{positionMessage(10, `"1":{"Status":"OnTrack","X":0,"Y":0,"Z":0}`)},
+			[]Messages.PositionStatus{}},
+		{"found again", []connection.Payload{
+			positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`),
+			positionMessage(11, `"1":{"Status":"OnTrack","X":0,"Y":0,"Z":0}`),
+			positionMessage(12, `"1":{"Status":"OffTrack","X":100,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack, Messages.PositionNoFix, Messages.PositionOffTrack}},
+		// The other car keeps sending so there is something to time out against
+		{"entries stop before the timeout", []connection.Payload{
+			positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`),
+			positionMessage(14.5, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack}},
+		{"entries stop", []connection.Payload{
+			positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`),
+			positionMessage(15, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`),
+			positionMessage(20, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`)},
+			[]Messages.PositionStatus{Messages.PositionOnTrack, Messages.PositionNoFix}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Location, Messages.RaceSession)
+			session.process(test.messages...)
+
+			actual := locationStatuses(session.output.locations, 1)
+			if fmt.Sprint(actual) != fmt.Sprint(test.expected) {
+				t.Errorf("Expected %v but got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+// The timeout uses the time of the location data rather than when it was received
+func TestPositionNoFixTimestamp(t *testing.T) {
+	session := createTestSession(parser.Location, Messages.RaceSession)
+	session.process(
+		positionMessage(10, `"1":{"Status":"OnTrack","X":100,"Y":200,"Z":0}`, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`),
+		positionMessage(16, `"2":{"Status":"OnTrack","X":300,"Y":200,"Z":0}`))
+
+	last := session.output.locations[len(session.output.locations)-1]
+	if last.DriverNumber != 1 || last.Status != Messages.PositionNoFix || !last.Timestamp.Equal(sessionTime(16)) {
+		t.Errorf("Expected no fix for car 1 at 16 seconds but got %+v", last)
+	}
+}
@@ -0,0 +1,108 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func qualifyingSession() *testSession {
+	session := createTestSession(parser.Event|parser.Timing, Messages.QualifyingSession)
+
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(0), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Qualifying"}`),
+		driverListMessage(sessionTime(0), 1, 2, 3, 4, 5),
+		message(connection.TimingDataFile, sessionTime(1), `{"NoEntries":[5,3,1],"Lines":{
+			"1":{"Position":"1","BestLapTimes":[{"Value":"1:30.000"}]},
+			"2":{"Position":"2","BestLapTimes":[{"Value":"1:30.100"}]},
+			"3":{"Position":"3","BestLapTimes":[{"Value":"1:30.200"}]},
+			"4":{"Position":"4","BestLapTimes":[{"Value":"1:30.300"}]},
+			"5":{"Position":"5","BestLapTimes":[{"Value":"1:30.400"}]}}}`))
+
+	return session
+}
+
+func TestQualifyingEliminationOrder(t *testing.T) {
+	// Map order is random so repeat to make sure the order doesn't change
+	for x := 0; x < 20; x++ {
+		session := qualifyingSession()
+
+		session.process(message(connection.TimingDataFile, sessionTime(600), `{"Lines":{
+			"4":{"KnockedOut":true},
+			"5":{"KnockedOut":true}}}`))
+
+		results := session.parser.QualifyingResults().Results
+		if len(results) != 5 {
+			t.Fatalf("Expected 5 results but got %d", len(results))
+		}
+
+		for _, result := range results {
+			expected := 0
+			switch result.Number {
+			case 5:
+				expected = 1
+			case 4:
+				expected = 2
+			}
+
+			if result.EliminationOrder != expected {
+				t.Fatalf("Expected car %d to have elimination order %d but got %d",
+					result.Number, expected, result.EliminationOrder)
+			}
+		}
+	}
+}
+
+func TestQualifyingLateKnockedOut(t *testing.T) {
+	session := qualifyingSession()
+
+	session.process(
+		message(connection.SessionDataFile, sessionTime(700), `{"Series":{"1":{"Utc":"2023-03-05T15:11:40","QualifyingPart":2}}}`),
+		// The flag for the end of Q1 arrives after Q2 has started
+		message(connection.TimingDataFile, sessionTime(701), `{"Lines":{
+			"4":{"KnockedOut":true},
+			"5":{"KnockedOut":true}}}`),
+		message(connection.TimingDataFile, sessionTime(1200), `{"Lines":{
+			"3":{"Position":"3","KnockedOut":true}}}`))
+
+	for _, result := range session.parser.QualifyingResults().Results {
+		expected := 0
+		switch result.Number {
+		case 4, 5:
+			expected = 1
+		case 3:
+			expected = 2
+		}
+
+		if result.KnockedOutInPart != expected {
+			t.Errorf("Expected car %d to be knocked out in part %d but got %d", result.Number, expected, result.KnockedOutInPart)
+		}
+	}
+}
+
+func TestQualifyingResetForNewSession(t *testing.T) {
+	session := qualifyingSession()
+
+	session.process(message(connection.SessionInfoFile, sessionTime(3600), `{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race"}`))
+
+	results := session.parser.QualifyingResults()
+	if len(results.Results) != 0 {
+		t.Errorf("Expected no qualifying results after the session changed but got %d", len(results.Results))
+	}
+}
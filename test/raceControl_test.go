@@ -0,0 +1,132 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestRaceControlFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		text     string
+		extra    string
+		expected Messages.RaceControlMessage
+	}{
+		{"track flag", "Flag", "GREEN LIGHT - PIT EXIT OPEN", `"Flag":"GREEN","Scope":"Track"`,
+			Messages.RaceControlMessage{Category: Messages.FlagCategory, Scope: Messages.TrackScope, Flag: Messages.GreenFlag}},
+		{"sector flag", "Flag", "YELLOW IN TRACK SECTOR 7", `"Flag":"YELLOW","Scope":"Sector","Sector":7`,
+			Messages.RaceControlMessage{Category: Messages.FlagCategory, Scope: Messages.SectorScope, Sector: 7, Flag: Messages.YellowFlag}},
+		{"driver flag", "Flag", "BLUE FLAG FOR CAR 4 (NOR) TIMED AT 15:10:00", `"Flag":"BLUE","Scope":"Driver","RacingNumber":"4","Lap":12`,
+			Messages.RaceControlMessage{Category: Messages.FlagCategory, Scope: Messages.DriverScope, RacingNumber: 4, Lap: 12, Flag: Messages.BlueFlag}},
+		{"drs enabled", "Drs", "DRS ENABLED", `"Status":"ENABLED"`,
+			Messages.RaceControlMessage{Category: Messages.DrsCategory, Status: Messages.EnabledStatus}},
+		{"drs disabled", "Drs", "DRS DISABLED", `"Status":"DISABLED"`,
+			Messages.RaceControlMessage{Category: Messages.DrsCategory, Status: Messages.DisabledStatus}},
+		{"safety car", "SafetyCar", "SAFETY CAR DEPLOYED", `"Status":"DEPLOYED","Mode":"SAFETY CAR"`,
+			Messages.RaceControlMessage{Category: Messages.SafetyCarCategory, Status: Messages.DeployedStatus, Mode: Messages.SafetyCarMode}},
+		{"safety car in this lap", "SafetyCar", "SAFETY CAR IN THIS LAP", `"Status":"IN THIS LAP","Mode":"SAFETY CAR"`,
+			Messages.RaceControlMessage{Category: Messages.SafetyCarCategory, Status: Messages.InThisLapStatus, Mode: Messages.SafetyCarMode}},
+		{"virtual safety car ending", "SafetyCar", "VIRTUAL SAFETY CAR ENDING", `"Status":"ENDING","Mode":"VIRTUAL SAFETY CAR"`,
+			Messages.RaceControlMessage{Category: Messages.SafetyCarCategory, Status: Messages.EndingStatus, Mode: Messages.VirtualSafetyCarMode}},
+		{"car event", "CarEvent", "CAR 4 (NOR) STOPPED", `"RacingNumber":4,"Lap":3`,
+			Messages.RaceControlMessage{Category: Messages.CarEventCategory, RacingNumber: 4, Lap: 3}},
+		{"other", "Other", "RISK OF RAIN FOR F1 RACE IS 10%", "",
+			Messages.RaceControlMessage{Category: Messages.OtherCategory}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.RaceControl, Messages.RaceSession)
+			session.process(raceControlMessage(sessionTime(10), test.category, test.text, test.extra))
+
+			if len(session.output.raceControl) != 1 {
+				t.Fatalf("Expected 1 message but got %d", len(session.output.raceControl))
+			}
+
+			actual := session.output.raceControl[0]
+			if actual.Msg != test.text {
+				t.Errorf("Message is '%s', expected '%s'", actual.Msg, test.text)
+			}
+			if actual.Category != test.expected.Category || actual.Scope != test.expected.Scope ||
+				actual.Sector != test.expected.Sector || actual.RacingNumber != test.expected.RacingNumber ||
+				actual.Lap != test.expected.Lap || actual.Flag != test.expected.Flag {
+				t.Errorf("Got %s %s sector %d car %d lap %d %s, expected %s %s sector %d car %d lap %d %s",
+					actual.Category, actual.Scope, actual.Sector, actual.RacingNumber, actual.Lap, actual.Flag,
+					test.expected.Category, test.expected.Scope, test.expected.Sector, test.expected.RacingNumber,
+					test.expected.Lap, test.expected.Flag)
+			}
+			if actual.Status != test.expected.Status || actual.Mode != test.expected.Mode {
+				t.Errorf("Got %s %s, expected %s %s", actual.Status, actual.Mode, test.expected.Status, test.expected.Mode)
+			}
+			if session.log.Len() > 0 {
+				t.Errorf("Unexpected parse errors: %s", session.log.String())
+			}
+		})
+	}
+}
+
+// The structured fields drive the event state, not the message text
+func TestRaceControlEventState(t *testing.T) {
+	tests := []struct {
+		name      string
+		category  string
+		extra     string
+		drs       Messages.DRSState
+		safetyCar Messages.TrackState
+	}{
+		{"drs enabled", "Drs", `"Status":"ENABLED"`, Messages.DRSEnabled, Messages.Clear},
+		{"drs disabled", "Drs", `"Status":"DISABLED"`, Messages.DRSDisabled, Messages.Clear},
+		{"safety car", "SafetyCar", `"Status":"DEPLOYED","Mode":"SAFETY CAR"`, Messages.DRSDisabled, Messages.SafetyCar},
+		{"safety car ending", "SafetyCar", `"Status":"IN THIS LAP","Mode":"SAFETY CAR"`, Messages.DRSUnknown, Messages.SafetyCarEnding},
+		{"virtual safety car", "SafetyCar", `"Status":"DEPLOYED","Mode":"VIRTUAL SAFETY CAR"`, Messages.DRSDisabled, Messages.VirtualSafetyCar},
+		{"virtual safety car ending", "SafetyCar", `"Status":"ENDING","Mode":"VIRTUAL SAFETY CAR"`, Messages.DRSUnknown, Messages.VirtualSafetyCarEnding},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Event, Messages.RaceSession)
+
+			// The text doesn't match the fields so we know the fields were used
+			session.process(raceControlMessage(sessionTime(10), test.category, "MESSAGE", test.extra))
+
+			if len(session.output.events) == 0 {
+				t.Fatal("Expected an event update")
+			}
+
+			event := session.output.events[len(session.output.events)-1]
+			if event.DRSEnabled != test.drs || event.SafetyCar != test.safetyCar {
+				t.Errorf("Got DRS %s and %s, expected DRS %s and %s", event.DRSEnabled, event.SafetyCar, test.drs, test.safetyCar)
+			}
+		})
+	}
+}
+
+func TestRaceControlUnknownStatusAndMode(t *testing.T) {
+	session := createTestSession(parser.RaceControl, Messages.RaceSession)
+	session.process(raceControlMessage(sessionTime(10), "SafetyCar", "SAFETY CAR", `"Status":"PARKED","Mode":"MEDICAL CAR"`))
+
+	actual := session.output.raceControl[0]
+	if actual.Status != Messages.NoStatus || actual.Mode != Messages.NoMode {
+		t.Errorf("Got %s %s, expected no status or mode", actual.Status, actual.Mode)
+	}
+	if session.log.Len() == 0 {
+		t.Error("Expected errors for the unknown status and mode")
+	}
+}
@@ -77,6 +77,14 @@ func (d *dummyFlowControl) AddTelemetry(timing Messages.Telemetry)
 func (d *dummyFlowControl) AddLocation(timing Messages.Location)                          {}
 func (d *dummyFlowControl) AddRadio(timing Messages.Radio)                                {}
 func (d *dummyFlowControl) AddDrivers(driver Messages.Drivers)                            {}
+func (d *dummyFlowControl) AddLap(lap Messages.Lap)                                       {}
+func (d *dummyFlowControl) AddClassification(classification Messages.Classification)      {}
+func (d *dummyFlowControl) AddPenalty(penalty Messages.Incident)                          {}
+func (d *dummyFlowControl) AddLocationFrame(frame Messages.LocationFrame)                 {}
+func (d *dummyFlowControl) AddTelemetryFrame(frame Messages.TelemetryFrame)               {}
+func (d *dummyFlowControl) AddCarSample(sample Messages.CarSample)                        {}
+func (d *dummyFlowControl) AddIntervals(intervals Messages.Intervals)                     {}
+func (d *dummyFlowControl) AddLapCorners(corners Messages.LapCorners)                     {}
 func (d *dummyFlowControl) IncrementLap()                                                 {}
 func (d *dummyFlowControl) IncrementTime(duration time.Duration)                          {}
 func (d *dummyFlowControl) SkipToSessionStart(start time.Time)                            {}
@@ -0,0 +1,184 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+	"testing"
+)
+
+func sectorFlagMessage(seconds float64, flag string, sector int) connection.Payload {
+	return raceControlMessage(sessionTime(seconds), "Flag", fmt.Sprintf("%s IN TRACK SECTOR %d", flag, sector),
+		fmt.Sprintf(`"Flag":"%s","Scope":"Sector","Sector":%d`, flag, sector))
+}
+
+func TestMarshalSectorFlags(t *testing.T) {
+	session := createTestSession(parser.Event, Messages.RaceSession)
+
+	session.process(
+		// Some sessions number the sectors from 0
+		sectorFlagMessage(10, "YELLOW", 0),
+		sectorFlagMessage(11, "DOUBLE YELLOW", 5),
+		sectorFlagMessage(20, "CLEAR", 5))
+
+	event := session.output.events[len(session.output.events)-1]
+	if event.MarshalSectorFlags[0] != Messages.YellowFlag || event.MarshalSectorFlags[5] != Messages.GreenFlag {
+		t.Errorf("Unexpected sector flags %v", event.MarshalSectorFlags)
+	}
+
+	// Without a track map each sector is shown on the segment with the same number, counting from 0 for this session
+	if event.SegmentFlags[0] != Messages.YellowFlag || event.SegmentFlags[5] != Messages.GreenFlag {
+		t.Errorf("Unexpected segment flags %v", event.SegmentFlags)
+	}
+
+	history := session.parser.SectorFlagHistory(5)
+	if len(history) != 2 || history[0].Flag != Messages.DoubleYellowFlag || history[1].Flag != Messages.GreenFlag {
+		t.Errorf("Unexpected history for sector 5: %v", history)
+	}
+
+	// A track clear resets every sector we have seen
+	session.process(raceControlMessage(sessionTime(30), "Flag", "TRACK CLEAR", `"Flag":"CLEAR","Scope":"Track"`))
+
+	history = session.parser.SectorFlagHistory(0)
+	if len(history) != 2 || history[1].Flag != Messages.GreenFlag {
+		t.Errorf("Unexpected history for sector 0: %v", history)
+	}
+}
+
+func TestMarshalSectorsOnSegments(t *testing.T) {
+	session := createTestSession(parser.Event, Messages.RaceSession)
+
+	// A 400m square with a segment along each side and two marshal sectors, the first ends half way along the
+	// second side
+	session.parser.UseTrackMap(trackMap.TrackMap{
+		Outline: []trackMap.Point{{X: 0, Y: 0}, {X: 4000, Y: 0}, {X: 4000, Y: 4000}, {X: 0, Y: 4000}},
+		Segments: []trackMap.Boundary{
+			{Number: 1, Point: trackMap.Point{X: 4000, Y: 0}},
+			{Number: 2, Point: trackMap.Point{X: 4000, Y: 4000}},
+			{Number: 3, Point: trackMap.Point{X: 0, Y: 4000}},
+			{Number: 4, Point: trackMap.Point{X: 0, Y: 0}},
+		},
+		TotalSegments: 4,
+		MarshalSectors: []trackMap.Boundary{
+			{Number: 1, Point: trackMap.Point{X: 4000, Y: 2000}},
+			{Number: 2, Point: trackMap.Point{X: 0, Y: 0}},
+		},
+	})
+
+	tests := []struct {
+		message  connection.Payload
+		expected [4]Messages.FlagState
+	}{
+		{sectorFlagMessage(10, "YELLOW", 1),
+			[4]Messages.FlagState{Messages.YellowFlag, Messages.YellowFlag, Messages.NoFlag, Messages.NoFlag}},
+		{sectorFlagMessage(20, "DOUBLE YELLOW", 2),
+			[4]Messages.FlagState{Messages.YellowFlag, Messages.DoubleYellowFlag, Messages.DoubleYellowFlag, Messages.DoubleYellowFlag}},
+		{sectorFlagMessage(30, "CLEAR", 2),
+			[4]Messages.FlagState{Messages.YellowFlag, Messages.YellowFlag, Messages.GreenFlag, Messages.GreenFlag}},
+		{sectorFlagMessage(40, "CLEAR", 1),
+			[4]Messages.FlagState{Messages.GreenFlag, Messages.GreenFlag, Messages.GreenFlag, Messages.GreenFlag}},
+	}
+
+	for _, test := range tests {
+		session.process(test.message)
+
+		event := session.output.events[len(session.output.events)-1]
+		segments := [4]Messages.FlagState(event.SegmentFlags[:4])
+		if segments != test.expected {
+			t.Errorf("Expected segment flags %v but got %v", test.expected, segments)
+		}
+	}
+
+	history := session.parser.SectorFlagHistory(2)
+	if len(history) == 0 || len(history[0].Segments) != 3 {
+		t.Errorf("Expected sector 2 to cover 3 segments but got %v", history)
+	}
+}
+
+// Without a saved track map the sectors are shown on the segments with the same number
+func TestSectorFlagsWithoutTrackMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []connection.Payload
+		expected [4]Messages.FlagState
+	}{
+		{"yellow", []connection.Payload{sectorFlagMessage(10, "YELLOW", 2)},
+			[4]Messages.FlagState{Messages.NoFlag, Messages.YellowFlag, Messages.NoFlag, Messages.NoFlag}},
+		{"red and double yellow", []connection.Payload{sectorFlagMessage(10, "RED", 1), sectorFlagMessage(11, "DOUBLE YELLOW", 4)},
+			[4]Messages.FlagState{Messages.RedFlag, Messages.NoFlag, Messages.NoFlag, Messages.DoubleYellowFlag}},
+		{"sector cleared", []connection.Payload{sectorFlagMessage(10, "YELLOW", 3), sectorFlagMessage(20, "CLEAR", 3)},
+			[4]Messages.FlagState{Messages.NoFlag, Messages.NoFlag, Messages.GreenFlag, Messages.NoFlag}},
+		{"track clear", []connection.Payload{sectorFlagMessage(10, "YELLOW", 3),
+			raceControlMessage(sessionTime(20), "Flag", "TRACK CLEAR", `"Flag":"CLEAR","Scope":"Track"`)},
+			[4]Messages.FlagState{Messages.GreenFlag, Messages.GreenFlag, Messages.GreenFlag, Messages.GreenFlag}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Event, Messages.RaceSession)
+			session.process(test.messages...)
+
+			event := session.output.events[len(session.output.events)-1]
+			segments := [4]Messages.FlagState(event.SegmentFlags[:4])
+			if segments != test.expected {
+				t.Errorf("Expected segment flags %v but got %v", test.expected, segments)
+			}
+		})
+	}
+}
+
+// Race control doesn't always clear a sector so it is removed after 10 minutes without an update
+func TestSectorFlagExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		message connection.Payload
+		expired bool
+	}{
+		{"heartbeat before timeout", message(connection.HeartbeatFile, sessionTime(599), `{"Utc":"2023-03-05T15:09:59Z"}`), false},
+		{"heartbeat after timeout", message(connection.HeartbeatFile, sessionTime(600), `{"Utc":"2023-03-05T15:10:00Z"}`), true},
+		{"race control after timeout", raceControlMessage(sessionTime(700), "Other", "RISK OF RAIN", ""), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Event, Messages.RaceSession)
+			session.process(sectorFlagMessage(0, "DOUBLE YELLOW", 2), test.message)
+
+			event := session.output.events[len(session.output.events)-1]
+			history := session.parser.SectorFlagHistory(2)
+
+			if test.expired {
+				if event.MarshalSectorFlags[2] != Messages.NoFlag || event.SegmentFlags[1] != Messages.NoFlag {
+					t.Errorf("Expected the flag to expire but got %s %s", event.MarshalSectorFlags[2], event.SegmentFlags[1])
+				}
+				if len(history) != 2 || !history[1].Expired || history[1].Flag != Messages.NoFlag {
+					t.Errorf("Expected the expiry in the history but got %v", history)
+				}
+			} else {
+				if event.MarshalSectorFlags[2] != Messages.DoubleYellowFlag || event.SegmentFlags[1] != Messages.DoubleYellowFlag {
+					t.Errorf("Expected the flag to still be shown but got %s %s", event.MarshalSectorFlags[2], event.SegmentFlags[1])
+				}
+				if len(history) != 1 {
+					t.Errorf("Expected no expiry in the history but got %v", history)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+	"time"
+)
+
+func TestSessionInfoGmtOffset(t *testing.T) {
+	tests := []struct {
+		offset   string
+		expected time.Duration
+	}{
+		{"00:00:00", 0},
+		{"01:00:00", time.Hour},
+		{"-04:00:00", -4 * time.Hour},
+		{"+05:30:00", 5*time.Hour + 30*time.Minute},
+		{"-03:30:15", -(3*time.Hour + 30*time.Minute + 15*time.Second)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.offset, func(t *testing.T) {
+			session := createTestSession(parser.Event, Messages.RaceSession)
+
+			session.process(message(connection.SessionInfoFile, sessionTime(0), fmt.Sprintf(
+				`{"Meeting":{"Name":"Test Grand Prix","Location":"Test"},"Name":"Race","StartDate":"2023-03-05T18:00:00","GmtOffset":"%s"}`,
+				test.offset)))
+
+			info := session.parser.SessionInfo()
+			if info.GmtOffset != test.expected {
+				t.Errorf("Expected an offset of %v but got %v", test.expected, info.GmtOffset)
+			}
+
+			// Start dates are local to the circuit
+			expectedStart := time.Date(2023, 3, 5, 18, 0, 0, 0, time.UTC).Add(-test.expected)
+			if !info.StartDate.Equal(expectedStart) {
+				t.Errorf("Expected a start of %v but got %v", expectedStart, info.StartDate)
+			}
+
+			if session.log.Len() > 0 {
+				t.Errorf("Unexpected errors: %s", session.log.String())
+			}
+		})
+	}
+}
+
+func TestSessionInfoInvalidGmtOffset(t *testing.T) {
+	session := createTestSession(parser.Event, Messages.RaceSession)
+
+	session.process(message(connection.SessionInfoFile, sessionTime(0),
+		`{"Meeting":{"Name":"Test Grand Prix"},"Name":"Race","GmtOffset":"one hour"}`))
+
+	if session.log.Len() == 0 {
+		t.Error("Expected an error for an invalid offset")
+	}
+}
+
+func TestSessionInfoPartialUpdate(t *testing.T) {
+	session := createTestSession(parser.Event, Messages.RaceSession)
+
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(0),
+			`{"Meeting":{"Name":"Test Grand Prix","Location":"Test"},"Name":"Race","GmtOffset":"01:00:00"}`),
+		message(connection.SessionInfoFile, sessionTime(7200), `{"ArchiveStatus":{"Status":"Complete"}}`))
+
+	info := session.parser.SessionInfo()
+	if info.Name != "Race" || info.Type != Messages.RaceSession || info.Meeting.Name != "Test Grand Prix" {
+		t.Errorf("Expected the session to be kept but got %v", info)
+	}
+	if info.ArchiveStatus != "Complete" || info.GmtOffset != time.Hour {
+		t.Errorf("Expected the archive status to be updated and the offset kept but got %v", info)
+	}
+}
+
+// Until the feed has sent a start date or an offset they are left empty so the values for the event are used instead
+func TestSessionInfoMissingStartAndOffset(t *testing.T) {
+	session := createTestSession(parser.Event, Messages.RaceSession)
+
+	session.process(message(connection.SessionInfoFile, sessionTime(0),
+		`{"Meeting":{"Name":"Test Grand Prix","Location":"Test"},"Name":"Race"}`))
+
+	info := session.parser.SessionInfo()
+	if !info.StartDate.IsZero() || info.Timezone != nil {
+		t.Errorf("Expected no start date or timezone but got %v and %v", info.StartDate, info.Timezone)
+	}
+
+	session.process(message(connection.SessionInfoFile, sessionTime(60),
+		`{"StartDate":"2023-03-05T18:00:00","GmtOffset":"03:00:00"}`))
+
+	info = session.parser.SessionInfo()
+	if !info.StartDate.Equal(testSessionStart) {
+		t.Errorf("Expected a start date of %v but got %v", testSessionStart, info.StartDate)
+	}
+	if info.Timezone == nil || info.Timezone.String() != "Test" {
+		t.Errorf("Expected the circuit timezone but got %v", info.Timezone)
+	}
+}
@@ -0,0 +1,152 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+	"time"
+)
+
+// The session is due to start at 15:00 UTC, 18:00 at the circuit
+func sessionTimeInfo(seconds float64, name string) connection.Payload {
+	return message(connection.SessionInfoFile, sessionTime(seconds),
+		`{"Meeting":{"Name":"Test Grand Prix","Location":"Test"},"Name":"`+name+`","StartDate":"2023-03-05T18:00:00","GmtOffset":"03:00:00"}`)
+}
+
+func TestSessionTimeReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		messages    []connection.Payload
+		sessionTime time.Duration
+		leaderLap   int
+	}{
+		{"before the start", []connection.Payload{timingMessage(sessionTime(-60), 1, `{"Position":"1"}`)},
+			-time.Minute, 1},
+		{"after the due start", []connection.Payload{timingMessage(sessionTime(90), 1, `{"Position":"1"}`)},
+			90 * time.Second, 1},
+		{"started late", []connection.Payload{
+			message(connection.SessionStatusFile, sessionTime(120), `{"Status":"Started"}`),
+			timingMessage(sessionTime(150), 1, `{"Position":"1"}`)},
+			30 * time.Second, 1},
+		{"leader lap from the lap count", []connection.Payload{
+			message(connection.LapCountFile, sessionTime(100), `{"CurrentLap":3,"TotalLaps":57}`),
+			timingMessage(sessionTime(110), 1, `{"Position":"2"}`)},
+			110 * time.Second, 3},
+		{"leader lap from the leader", []connection.Payload{
+			timingMessage(sessionTime(100), 2, `{"Position":"1","NumberOfLaps":4}`),
+			timingMessage(sessionTime(110), 1, `{"Position":"2"}`)},
+			110 * time.Second, 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+			session.process(append([]connection.Payload{
+				sessionTimeInfo(-3600, "Race"),
+				driverListMessage(sessionTime(-3600), 1, 2)},
+				test.messages...)...)
+
+			timing := lastTiming(session, 1)
+			expected := test.messages[len(test.messages)-1].Timestamp
+			if timing.Timestamp.Format("2006-01-02T15:04:05.999Z") != expected || timing.Timestamp.Location() != time.UTC {
+				t.Errorf("Timestamp is %v, expected %s in UTC", timing.Timestamp, expected)
+			}
+			if timing.SessionTime != test.sessionTime {
+				t.Errorf("Session time is %v, expected %v", timing.SessionTime, test.sessionTime)
+			}
+			if timing.LeaderLap != test.leaderLap {
+				t.Errorf("Leader lap is %d, expected %d", timing.LeaderLap, test.leaderLap)
+			}
+		})
+	}
+}
+
+func TestSessionTimeCatchup(t *testing.T) {
+	tests := []struct {
+		name        string
+		clock       string
+		timestamp   time.Time
+		sessionTime time.Duration
+	}{
+		{"heartbeat", `"Heartbeat":{"Utc":"2023-03-05T15:10:00Z"},"ExtrapolatedClock":{"Utc":"2023-03-05T15:09:00Z","Remaining":"01:00:00","Extrapolating":true}`,
+			sessionTime(600), 10 * time.Minute},
+		{"clock", `"ExtrapolatedClock":{"Utc":"2023-03-05T15:09:00Z","Remaining":"01:00:00","Extrapolating":true}`,
+			sessionTime(540), 9 * time.Minute},
+		{"no time", `"WeatherData":{"AirTemp":"20.0"}`, time.Time{}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+			session.process(connection.Payload{
+				Name: connection.CatchupFile,
+				Data: []byte(`{` + test.clock + `,
+					"SessionInfo":{"Meeting":{"Name":"Test Grand Prix","Location":"Test"},"Name":"Race","StartDate":"2023-03-05T18:00:00","GmtOffset":"03:00:00"},
+					"DriverList":{"1":{"RacingNumber":"1","Tla":"D01","Line":1}},
+					"SessionStatus":{"Status":"Started"},
+					"LapCount":{"CurrentLap":7,"TotalLaps":57},
+					"TimingData":{"Lines":{"1":{"Position":"1","NumberOfLaps":6}}}}`),
+			})
+
+			timing := lastTiming(session, 1)
+			if !timing.Timestamp.Equal(test.timestamp) {
+				t.Errorf("Timestamp is %v, expected %v", timing.Timestamp, test.timestamp)
+			}
+			// Catching up doesn't know when the session started so uses when it was due to start
+			if timing.SessionTime != test.sessionTime {
+				t.Errorf("Session time is %v, expected %v", timing.SessionTime, test.sessionTime)
+			}
+			if timing.LeaderLap != 7 {
+				t.Errorf("Leader lap is %d, expected 7", timing.LeaderLap)
+			}
+		})
+	}
+}
+
+// A new session is timed from its own start
+func TestSessionTimeRestart(t *testing.T) {
+	session := createTestSession(parser.Timing, Messages.RaceSession)
+	session.process(
+		sessionTimeInfo(-3600, "Qualifying"),
+		driverListMessage(sessionTime(-3600), 1),
+		message(connection.SessionStatusFile, sessionTime(60), `{"Status":"Started"}`),
+		timingMessage(sessionTime(120), 1, `{"Position":"1"}`))
+
+	if timing := lastTiming(session, 1); timing.SessionTime != time.Minute {
+		t.Errorf("Session time is %v, expected 1m", timing.SessionTime)
+	}
+
+	// The race is due to start 2 hours later and starts 5 minutes late
+	session.process(
+		message(connection.SessionInfoFile, sessionTime(3600),
+			`{"Name":"Race","StartDate":"2023-03-05T20:00:00","GmtOffset":"03:00:00"}`),
+		timingMessage(sessionTime(7100), 1, `{"Position":"1"}`))
+
+	if timing := lastTiming(session, 1); timing.SessionTime != -100*time.Second {
+		t.Errorf("Session time is %v, expected -1m40s before the race start", timing.SessionTime)
+	}
+
+	session.process(
+		message(connection.SessionStatusFile, sessionTime(7500), `{"Status":"Started"}`),
+		timingMessage(sessionTime(7530), 1, `{"Position":"1"}`))
+
+	if timing := lastTiming(session, 1); timing.SessionTime != 30*time.Second {
+		t.Errorf("Session time is %v, expected 30s after the race started", timing.SessionTime)
+	}
+}
@@ -0,0 +1,105 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestSpeedTraps(t *testing.T) {
+	tests := []struct {
+		name string
+		trap Messages.SpeedTrapLocation
+	}{
+		{"I1", Messages.Intermediate1Trap},
+		{"I2", Messages.Intermediate2Trap},
+		{"FL", Messages.FinishLineTrap},
+		{"ST", Messages.StraightTrap},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(parser.Timing, Messages.RaceSession)
+
+			session.process(
+				driverListMessage(sessionTime(0), 1),
+				timingMessage(sessionTime(1), 1, fmt.Sprintf(
+					`{"Speeds":{"%s":{"Value":"312","PersonalFastest":true,"OverallFastest":false}}}`, test.name)),
+				message(connection.TimingStatsFile, sessionTime(2), fmt.Sprintf(
+					`{"Lines":{"1":{"BestSpeeds":{"%s":{"Value":"315","Position":3}}}}}`, test.name)))
+
+			timing := session.output.timing
+			if len(timing) != 2 {
+				t.Fatalf("Expected 2 timing updates but got %d", len(timing))
+			}
+
+			speed := timing[0].Speeds[test.trap]
+			if speed.Speed != 312 || !speed.PersonalFastest || speed.OverallFastest {
+				t.Errorf("Speed is %+v", speed)
+			}
+
+			ranking := timing[1].Speeds[test.trap]
+			if ranking.Speed != 312 || ranking.SessionBest != 315 || ranking.SessionBestPosition != 3 {
+				t.Errorf("Session best is %+v", ranking)
+			}
+
+			// The original fields are only for the speed trap on the straight
+			isStraight := test.trap == Messages.StraightTrap
+			if (timing[0].SpeedTrap == 312) != isStraight || timing[0].SpeedTrapPersonalFastest != isStraight {
+				t.Errorf("Speed trap is %d personal fastest %v", timing[0].SpeedTrap, timing[0].SpeedTrapPersonalFastest)
+			}
+
+			if session.log.Len() > 0 {
+				t.Errorf("Unexpected errors: %s", session.log.String())
+			}
+		})
+	}
+}
+
+// Rankings are only sent again when they change
+func TestSpeedTrapRankingUnchanged(t *testing.T) {
+	session := createTestSession(parser.Timing, Messages.RaceSession)
+	rankings := `{"Lines":{"1":{"BestSpeeds":{"ST":{"Value":"320","Position":1}}}}}`
+
+	session.process(
+		driverListMessage(sessionTime(0), 1),
+		message(connection.TimingStatsFile, sessionTime(1), rankings),
+		message(connection.TimingStatsFile, sessionTime(2), rankings),
+		message(connection.TimingStatsFile, sessionTime(3), `{"Lines":{"1":{"BestSpeeds":{"ST":{"Position":2}}}}}`))
+
+	timing := session.output.timing
+	if len(timing) != 2 {
+		t.Fatalf("Expected 2 timing updates but got %d", len(timing))
+	}
+	if timing[1].Speeds[Messages.StraightTrap].SessionBest != 320 || timing[1].Speeds[Messages.StraightTrap].SessionBestPosition != 2 {
+		t.Errorf("Session best is %+v", timing[1].Speeds[Messages.StraightTrap])
+	}
+}
+
+func TestSpeedTrapUnknown(t *testing.T) {
+	session := createTestSession(parser.Timing, Messages.RaceSession)
+	session.process(
+		driverListMessage(sessionTime(0), 1),
+		timingMessage(sessionTime(1), 1, `{"Speeds":{"XX":{"Value":"312"}}}`))
+
+	if session.log.Len() == 0 {
+		t.Error("Expected the unknown speed trap to be logged")
+	}
+}
@@ -0,0 +1,58 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"testing"
+)
+
+func TestStintKeepsCompoundUntilReported(t *testing.T) {
+	session := createTestSession(parser.Timing, Messages.RaceSession)
+
+	session.process(
+		driverListMessage(sessionTime(0), 1),
+		message(connection.TimingAppDataFile, sessionTime(1),
+			`{"Lines":{"1":{"Stints":[{"Compound":"MEDIUM","New":"true","StartLaps":0,"TotalLaps":12,"LapFlags":0}]}}}`),
+		// The new stint starts before the compound is known
+		message(connection.TimingAppDataFile, sessionTime(2),
+			`{"Lines":{"1":{"Stints":{"1":{"TotalLaps":0}}}}}`))
+
+	timing := session.output.timing
+	if len(timing) != 2 {
+		t.Fatalf("Expected 2 timing updates but got %d", len(timing))
+	}
+
+	if timing[1].Tire != Messages.Medium {
+		t.Errorf("Expected the previous compound to be kept but got %v", timing[1].Tire)
+	}
+	if len(timing[1].Stints) != 2 {
+		t.Fatalf("Expected 2 stints but got %d", len(timing[1].Stints))
+	}
+
+	session.process(message(connection.TimingAppDataFile, sessionTime(3),
+		`{"Lines":{"1":{"Stints":{"1":{"Compound":"HARD","New":"true"}}}}}`))
+
+	latest := session.output.timing[2]
+	if latest.Tire != Messages.Hard || latest.LapsOnTire != 0 {
+		t.Errorf("Expected new hard tires but got %v with %d laps", latest.Tire, latest.LapsOnTire)
+	}
+	if latest.Stints[0].Compound != Messages.Medium || latest.Stints[0].TotalLaps != 12 {
+		t.Errorf("Expected the first stint to be unchanged but got %v", latest.Stints[0])
+	}
+}
@@ -0,0 +1,115 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Samples every 0.25 seconds from 10 seconds, the engine is off for the 2 seconds from 11 seconds
+func policyMessages(driver int) []connection.Payload {
+	var result []connection.Payload
+	for seconds := 10.0; seconds < 14; seconds += 0.25 {
+		rpm := 11000
+		if seconds >= 11 && seconds < 13 {
+			rpm = 0
+		}
+		result = append(result, carDataMessage(seconds, driver, fmt.Sprintf(`"0":%d`, rpm)))
+	}
+	return result
+}
+
+func sampleTimes(telemetry []Messages.Telemetry, driver int) []float64 {
+	result := make([]float64, 0)
+	for _, sample := range telemetry {
+		if sample.DriverNumber == driver {
+			result = append(result, sample.Timestamp.Sub(testSessionStart).Seconds())
+		}
+	}
+	return result
+}
+
+func TestTelemetryPolicy(t *testing.T) {
+	running := []float64{10, 10.25, 10.5, 10.75, 13, 13.25, 13.5, 13.75}
+
+	tests := []struct {
+		name     string
+		policy   parser.TelemetryPolicy
+		expected []float64
+	}{
+		{"drop engine off", parser.DefaultTelemetryPolicy(), running},
+		{"pass all", parser.TelemetryPolicy{Mode: parser.PassAll},
+			[]float64{10, 10.25, 10.5, 10.75, 11, 11.25, 11.5, 11.75, 12, 12.25, 12.5, 12.75, 13, 13.25, 13.5, 13.75}},
+		// The sample when the engine stops is always sent then one a second while it is off
+		{"throttled idle", parser.TelemetryPolicy{Mode: parser.ThrottledIdle, IdleInterval: time.Second},
+			[]float64{10, 10.25, 10.5, 10.75, 11, 12, 13, 13.25, 13.5, 13.75}},
+		{"throttled idle half a second", parser.TelemetryPolicy{Mode: parser.ThrottledIdle, IdleInterval: 500 * time.Millisecond},
+			[]float64{10, 10.25, 10.5, 10.75, 11, 11.5, 12, 12.5, 13, 13.25, 13.5, 13.75}},
+		{"decimation", parser.TelemetryPolicy{Mode: parser.DropEngineOff, DefaultDecimation: 2},
+			[]float64{10, 10.5, 13, 13.5}},
+		{"decimation of 1", parser.TelemetryPolicy{Mode: parser.DropEngineOff, DefaultDecimation: 1}, running},
+		{"decimation for another driver", parser.TelemetryPolicy{Mode: parser.DropEngineOff, Decimation: map[int]int{44: 4}},
+			running},
+		{"decimation for the driver", parser.TelemetryPolicy{Mode: parser.DropEngineOff, DefaultDecimation: 2,
+			Decimation: map[int]int{1: 4}}, []float64{10, 13}},
+		{"decimation while idle", parser.TelemetryPolicy{Mode: parser.PassAll, DefaultDecimation: 4},
+			[]float64{10, 11, 12, 13}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := telemetrySession(parser.Telemetry)
+			session.parser.SetTelemetryPolicy(test.policy)
+			session.process(policyMessages(1)...)
+
+			actual := sampleTimes(session.output.telemetry, 1)
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("Expected samples at %v but got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+// Each driver is decimated on their own samples
+func TestTelemetryPolicyPerDriver(t *testing.T) {
+	session := createTestSession(parser.Telemetry, Messages.RaceSession)
+	session.parser.SelectTelemetrySources([]int{1, 44})
+	session.parser.SetTelemetryPolicy(parser.TelemetryPolicy{Mode: parser.PassAll, Decimation: map[int]int{44: 8}})
+	session.process(append(policyMessages(1), policyMessages(44)...)...)
+
+	if len(sampleTimes(session.output.telemetry, 1)) != 16 {
+		t.Errorf("Expected every sample for driver 1 but got %v", sampleTimes(session.output.telemetry, 1))
+	}
+	if !reflect.DeepEqual(sampleTimes(session.output.telemetry, 44), []float64{10, 12}) {
+		t.Errorf("Expected every 8th sample for driver 44 but got %v", sampleTimes(session.output.telemetry, 44))
+	}
+}
+
+// Only the selected drivers are sent
+func TestTelemetrySources(t *testing.T) {
+	session := telemetrySession(parser.Telemetry)
+	session.process(carDataMessage(10, 1, `"0":11000`), carDataMessage(10, 44, `"0":11000`))
+
+	if len(session.output.telemetry) != 1 || session.output.telemetry[0].DriverNumber != 1 {
+		t.Errorf("Expected only driver 1 but got %v", session.output.telemetry)
+	}
+}
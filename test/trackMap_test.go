@@ -0,0 +1,331 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/f1gopher/f1gopherlib/connection"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"github.com/f1gopher/f1gopherlib/trackMap"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A square track 100m along each side, 400m a lap, starting at (0, 0) and going anti-clockwise
+const squareSide = 1000.0
+
+func squarePoint(fraction float64) trackMap.Point {
+	fraction = fraction - math.Floor(fraction)
+	side := int(fraction * 4)
+	along := (fraction*4 - float64(side)) * squareSide
+
+	switch side {
+	case 0:
+		return trackMap.Point{X: along, Y: 0}
+	case 1:
+		return trackMap.Point{X: squareSide, Y: along}
+	case 2:
+		return trackMap.Point{X: squareSide - along, Y: squareSide}
+	default:
+		return trackMap.Point{X: 0, Y: squareSide - along}
+	}
+}
+
+func squareOutline() []trackMap.Point {
+	return []trackMap.Point{{X: 0, Y: 0}, {X: squareSide, Y: 0}, {X: squareSide, Y: squareSide}, {X: 0, Y: squareSide}}
+}
+
+// Lap of the square taking 80 seconds with a location every half a second
+const squareLapTime = 80.0
+
+func squareLocation(driver int, seconds float64) Messages.Location {
+	point := squarePoint(seconds / squareLapTime)
+	return Messages.Location{
+		Timestamp:    sessionTime(seconds),
+		DriverNumber: driver,
+		X:            point.X,
+		Y:            point.Y,
+		Status:       Messages.PositionOnTrack,
+	}
+}
+
+func builderTiming(driver int, seconds float64, lap int, inPit bool, segments int) Messages.Timing {
+	result := Messages.Timing{
+		Timestamp: sessionTime(seconds),
+		Number:    driver,
+		Lap:       lap,
+		InPit:     inPit,
+	}
+	for x := 0; x < segments; x++ {
+		result.Segment[x] = Messages.GreenSegment
+	}
+	return result
+}
+
+func TestBuilderComplete(t *testing.T) {
+	builder := trackMap.CreateBuilder()
+	builder.SetSegments(1, 1, 1)
+
+	// A clean lap from 80 to 160 seconds crossing the segment lines a third of the way round then a pit stop
+	timing := []Messages.Timing{
+		builderTiming(1, 0, 1, false, 0),
+		builderTiming(1, 80, 2, false, 0),
+		builderTiming(1, 80+squareLapTime/3, 2, false, 1),
+		builderTiming(1, 80+squareLapTime*2/3, 2, false, 2),
+		builderTiming(1, 160, 3, false, 0),
+		builderTiming(1, 170, 3, true, 0),
+		builderTiming(1, 200, 3, false, 0),
+	}
+	for _, driver := range timing {
+		builder.AddTiming(driver)
+	}
+
+	for seconds := 0.0; seconds <= 160; seconds += 0.5 {
+		builder.AddLocation(squareLocation(1, seconds))
+	}
+	if builder.Complete() {
+		t.Fatal("Complete before the pit lane has been found")
+	}
+
+	for seconds := 160.5; seconds <= 210; seconds += 0.5 {
+		builder.AddLocation(squareLocation(1, seconds))
+	}
+	if !builder.Complete() {
+		t.Fatalf("Not complete: %+v", builder.TrackMap())
+	}
+
+	result := builder.TrackMap()
+	if !result.Complete() {
+		t.Fatalf("Track map not complete: %+v", result)
+	}
+	if len(builder.Outline()) != len(result.Outline) {
+		t.Errorf("Outline has %d points but the track map has %d", len(builder.Outline()), len(result.Outline))
+	}
+	if result.StartFinish != (trackMap.Point{X: 0, Y: 0}) {
+		t.Errorf("Start/finish at %+v", result.StartFinish)
+	}
+	if !reflect.DeepEqual(result.MarshalSectors, result.Segments) {
+		t.Errorf("Expected a marshal sector for each segment but got %+v", result.MarshalSectors)
+	}
+
+	// Sector flags are shown on the segments of the built map
+	session := createTestSession(parser.Event, Messages.RaceSession)
+	session.parser.UseTrackMap(result)
+	session.process(sectorFlagMessage(10, "YELLOW", 2))
+
+	event := session.output.events[len(session.output.events)-1]
+	segments := [3]Messages.FlagState(event.SegmentFlags[:3])
+	if segments != [3]Messages.FlagState{Messages.NoFlag, Messages.YellowFlag, Messages.NoFlag} {
+		t.Errorf("Unexpected segment flags %v", segments)
+	}
+}
+
+// A car that stays in the garage doesn't give a pit lane when it comes out
+func TestBuilderGarage(t *testing.T) {
+	builder := trackMap.CreateBuilder()
+
+	builder.AddTiming(builderTiming(1, 0, 1, false, 0))
+	builder.AddTiming(builderTiming(1, 1, 1, true, 0))
+
+	garage := 10 * time.Minute
+	for seconds := 0.0; seconds <= garage.Seconds(); seconds += 0.5 {
+		location := squareLocation(1, 0)
+		location.Timestamp = sessionTime(seconds)
+		builder.AddLocation(location)
+	}
+
+	builder.AddTiming(builderTiming(1, garage.Seconds()+0.5, 1, false, 0))
+	builder.AddLocation(squareLocation(1, garage.Seconds()+1))
+
+	if len(builder.TrackMap().PitLane) != 0 {
+		t.Errorf("Pit lane from a car in the garage")
+	}
+}
+
+func TestCreateLapDistance(t *testing.T) {
+	if trackMap.CreateLapDistance(nil) != nil {
+		t.Error("Lap distance for no outline")
+	}
+	if trackMap.CreateLapDistance([]trackMap.Point{{X: 1, Y: 1}}) != nil {
+		t.Error("Lap distance for a single point")
+	}
+
+	lapDistance := trackMap.CreateLapDistance(squareOutline())
+	if lapDistance == nil {
+		t.Fatal("No lap distance for the square")
+	}
+	if lapDistance.Length() != 400 {
+		t.Errorf("Square lap is %fm", lapDistance.Length())
+	}
+}
+
+func TestLapDistance(t *testing.T) {
+	lapDistance := trackMap.CreateLapDistance(squareOutline())
+
+	tests := []struct {
+		name     string
+		point    trackMap.Point
+		distance float64
+	}{
+		{"Start", trackMap.Point{X: 0, Y: 0}, 0},
+		{"Along the first side", trackMap.Point{X: 250, Y: 0}, 25},
+		{"First corner", trackMap.Point{X: squareSide, Y: 0}, 100},
+		{"Off the track", trackMap.Point{X: 500, Y: -50}, 50},
+		{"Third side", trackMap.Point{X: 400, Y: squareSide}, 260},
+		{"Last side", trackMap.Point{X: 0, Y: 100}, 390},
+		{"Outside a corner", trackMap.Point{X: squareSide + 100, Y: squareSide + 100}, 200},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			distance, fraction := lapDistance.Distance(test.point)
+			if math.Abs(distance-test.distance) > 0.001 {
+				t.Errorf("Distance is %f, expected %f", distance, test.distance)
+			}
+			if math.Abs(fraction-test.distance/400) > 0.00001 {
+				t.Errorf("Fraction is %f, expected %f", fraction, test.distance/400)
+			}
+		})
+	}
+}
+
+func TestLapDistanceWrap(t *testing.T) {
+	lapDistance := trackMap.CreateLapDistance(squareOutline())
+
+	tests := []struct {
+		name     string
+		distance float64
+		expected float64
+	}{
+		{"Within the lap", 150, 150},
+		{"Start", 0, 0},
+		{"End of the lap", 400, 0},
+		{"Past the line", 410, 10},
+		{"Several laps", 1250, 50},
+		{"Before the line", -10, 390},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			distance, fraction := lapDistance.Wrap(test.distance)
+			if math.Abs(distance-test.expected) > 0.001 {
+				t.Errorf("Distance is %f, expected %f", distance, test.expected)
+			}
+			if math.Abs(fraction-test.expected/400) > 0.00001 {
+				t.Errorf("Fraction is %f, expected %f", fraction, test.expected/400)
+			}
+		})
+	}
+}
+
+// A figure of eight 1km across so the track crosses itself in the middle
+func TestLapDistanceNear(t *testing.T) {
+	const side = 10000.0
+	lapDistance := trackMap.CreateLapDistance([]trackMap.Point{
+		{X: 0, Y: 0},
+		{X: side, Y: side},
+		{X: side, Y: 0},
+		{X: 0, Y: side},
+	})
+
+	diagonal := math.Sqrt2 * side / trackMap.UnitsPerMetre
+	firstCrossing := diagonal / 2
+	secondCrossing := diagonal + side/trackMap.UnitsPerMetre + diagonal/2
+	crossing := trackMap.Point{X: side / 2, Y: side / 2}
+
+	tests := []struct {
+		name     string
+		point    trackMap.Point
+		previous float64
+		distance float64
+	}{
+		{"First time through the crossing", crossing, firstCrossing - 50, firstCrossing},
+		{"Second time through the crossing", crossing, secondCrossing - 50, secondCrossing},
+		{"Previous distance past the line", trackMap.Point{X: 100, Y: 100}, lapDistance.Length() - 5, math.Sqrt2 * 10},
+		{"Previous distance on another part of the lap", trackMap.Point{X: side, Y: 5000}, firstCrossing, diagonal + 500},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			distance, _ := lapDistance.DistanceNear(test.point, test.previous)
+			if math.Abs(distance-test.distance) > 0.001 {
+				t.Errorf("Distance is %f, expected %f", distance, test.distance)
+			}
+		})
+	}
+}
+
+// Laps of the 4km square at 180km/h from 3910m crossing the line at 1.8 and 81.8 seconds with no gaps in the locations.
+// Starting at 3900m would put the car on (0, 0), which is no fix.
+func cleanLapMessages() []connection.Payload {
+	result := []connection.Payload{timingMessage(sessionTime(0), 1, `{"NumberOfLaps":1}`)}
+
+	for seconds := 0.0; seconds <= 100; seconds += 0.5 {
+		result = append(result, intervalLocations(seconds, map[int]trackMap.Point{1: intervalPoint(3910 + seconds*50)}))
+		result = append(result, carDataMessage(seconds+0.25, 1, `"0":11000,"2":180,"3":6,"4":100,"5":0`))
+
+		switch seconds {
+		case 2:
+			result = append(result, timingMessage(sessionTime(seconds), 1, `{"NumberOfLaps":2}`))
+		case 82:
+			result = append(result, timingMessage(sessionTime(seconds), 1, `{"NumberOfLaps":3}`))
+		}
+	}
+
+	return result
+}
+
+// Locations and telemetry have their lap distance from a map built from the feed when there isn't a saved one
+func TestLapDistanceWithoutSavedMap(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestedData parser.DataSource
+		distance      func(session *testSession) (float64, float64)
+	}{
+		{"location", parser.Location, func(session *testSession) (float64, float64) {
+			last := session.output.locations[len(session.output.locations)-1]
+			return last.LapDistance, last.LapFraction
+		}},
+		{"telemetry", parser.Telemetry, func(session *testSession) (float64, float64) {
+			last := session.output.telemetry[len(session.output.telemetry)-1]
+			return last.LapDistance, last.LapFraction
+		}},
+		{"car samples", parser.CarSamples, func(session *testSession) (float64, float64) {
+			last := session.output.samples[len(session.output.samples)-1]
+			return last.LapDistance, last.LapFraction
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			session := createTestSession(test.requestedData, Messages.RaceSession)
+			session.parser.SelectTelemetrySources([]int{1})
+			session.process(driverListMessage(sessionTime(0), 1))
+			session.process(cleanLapMessages()...)
+
+			// The last location is at 100 seconds, 910m into the lap
+			distance, fraction := test.distance(session)
+			if distance < 860 || distance > 960 {
+				t.Errorf("Lap distance is %fm, expected about 910m", distance)
+			}
+			if math.Abs(fraction-distance/intervalLapLength) > 0.01 {
+				t.Errorf("Lap fraction is %f for %fm", fraction, distance)
+			}
+		})
+	}
+}
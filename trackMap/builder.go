@@ -0,0 +1,365 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package trackMap
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// A lap with a gap in the locations bigger than this can't be used for the outline
+const maxLocationGap = 2 * time.Second
+
+// Keep this much history for cars that aren't doing laps
+const maxHistory = 5 * time.Minute
+
+// Laps with fewer locations than this are probably missing data
+const minOutlineLocations = 50
+
+type eventType int
+
+const (
+	lapEvent eventType = iota
+	segmentEvent
+	pitEntryEvent
+	pitExitEvent
+)
+
+// Timing changes are waiting for the location data for the same time which can arrive later
+type trackEvent struct {
+	timestamp time.Time
+	eventType eventType
+	segment   int
+}
+
+type driverTrace struct {
+	locations []Messages.Location
+	events    []trackEvent
+
+	// Timing we have already seen for the driver so we can find the changes
+	lap      int
+	inPit    bool
+	segments [Messages.MaxSegments]Messages.SegmentType
+
+	lapStart  time.Time
+	pitStart  time.Time
+	cleanLap  bool
+	hasTiming bool
+}
+
+// Builds a map of the track from car locations and the timing as cars cross the segment, sector and finish lines
+type Builder struct {
+	drivers map[int]*driverTrace
+
+	sector1Segments int
+	sector2Segments int
+	sector3Segments int
+
+	outline     []Point
+	pitLane     []Point
+	startFinish *Point
+	segments    map[int]Point
+
+	lock sync.Mutex
+}
+
+func CreateBuilder() *Builder {
+	return &Builder{
+		drivers:  make(map[int]*driverTrace),
+		segments: make(map[int]Point),
+	}
+}
+
+func (b *Builder) SetSegments(sector1 int, sector2 int, sector3 int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.sector1Segments = sector1
+	b.sector2Segments = sector2
+	b.sector3Segments = sector3
+}
+
+func (b *Builder) AddLocation(location Messages.Location) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	driver := b.driver(location.DriverNumber)
+
+	if len(driver.locations) > 0 && !location.Timestamp.After(driver.locations[len(driver.locations)-1].Timestamp) {
+		return
+	}
+	driver.locations = append(driver.locations, location)
+
+	b.processEvents(driver)
+	driver.trim()
+}
+
+func (b *Builder) AddTiming(timing Messages.Timing) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	driver := b.driver(timing.Number)
+
+	// Events only go once we have the locations for them so drop old ones if the locations have stopped
+	keepFrom := timing.Timestamp.Add(-maxHistory)
+	for len(driver.events) > 0 && driver.events[0].timestamp.Before(keepFrom) {
+		driver.events = driver.events[1:]
+	}
+
+	// Need to know the state before we can tell what has changed
+	if !driver.hasTiming {
+		driver.hasTiming = true
+		driver.lap = timing.Lap
+		driver.inPit = timing.InPit
+		driver.segments = timing.Segment
+		return
+	}
+
+	// Only a single lap means the car crossed the line, more is catching up on missed data
+	if timing.Lap == driver.lap+1 {
+		driver.events = append(driver.events, trackEvent{timestamp: timing.Timestamp, eventType: lapEvent})
+	}
+	driver.lap = timing.Lap
+
+	for x := range timing.Segment {
+		if timing.Segment[x] != Messages.None && driver.segments[x] == Messages.None &&
+			timing.Segment[x] != Messages.PitlaneSegment {
+			driver.events = append(driver.events, trackEvent{timestamp: timing.Timestamp, eventType: segmentEvent, segment: x})
+		}
+	}
+	driver.segments = timing.Segment
+
+	if timing.InPit != driver.inPit {
+		event := pitExitEvent
+		if timing.InPit {
+			event = pitEntryEvent
+		}
+		driver.events = append(driver.events, trackEvent{timestamp: timing.Timestamp, eventType: event})
+	}
+	driver.inPit = timing.InPit
+}
+
+// Has everything been found for the track, the same as TrackMap().Complete() without creating the map
+func (b *Builder) Complete() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	totalSegments := b.sector1Segments + b.sector2Segments + b.sector3Segments
+	if len(b.outline) == 0 || len(b.pitLane) == 0 || b.startFinish == nil ||
+		b.sector1Segments == 0 || b.sector2Segments == 0 || b.sector3Segments == 0 {
+		return false
+	}
+
+	// The last segment can end at the finish line
+	for segment := 0; segment < totalSegments-1; segment++ {
+		if _, exists := b.segments[segment]; !exists {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The outline of the track, empty until a clean lap has been found
+func (b *Builder) Outline() []Point {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]Point(nil), b.outline...)
+}
+
+// The path down the pit lane, empty until a car has driven through it
+func (b *Builder) PitLane() []Point {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return append([]Point(nil), b.pitLane...)
+}
+
+func (b *Builder) TrackMap() TrackMap {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	result := TrackMap{
+		Outline:       append([]Point(nil), b.outline...),
+		PitLane:       append([]Point(nil), b.pitLane...),
+		TotalSegments: b.sector1Segments + b.sector2Segments + b.sector3Segments,
+	}
+
+	for segment, point := range b.segments {
+		result.Segments = append(result.Segments, Boundary{Number: segment + 1, Point: point})
+	}
+
+	// The last segment ends at the finish line
+	if _, exists := b.segments[result.TotalSegments-1]; !exists && b.startFinish != nil && result.TotalSegments > 0 {
+		result.Segments = append(result.Segments, Boundary{Number: result.TotalSegments, Point: *b.startFinish})
+	}
+	sort.Slice(result.Segments, func(i, j int) bool {
+		return result.Segments[i].Number < result.Segments[j].Number
+	})
+
+	// The feed doesn't say where the marshal sectors are so start with one for each segment, which is how most
+	// tracks are split up
+	result.MarshalSectors = append([]Boundary(nil), result.Segments...)
+
+	// Sectors end at the end of their last segment and the last sector ends at the finish line
+	if point, exists := b.segments[b.sector1Segments-1]; exists && b.sector1Segments > 0 {
+		result.Sectors = append(result.Sectors, Boundary{Number: 1, Point: point})
+	}
+	if point, exists := b.segments[b.sector1Segments+b.sector2Segments-1]; exists && b.sector2Segments > 0 {
+		result.Sectors = append(result.Sectors, Boundary{Number: 2, Point: point})
+	}
+	if b.startFinish != nil {
+		result.StartFinish = *b.startFinish
+
+		if len(result.Sectors) == 2 {
+			result.Sectors = append(result.Sectors, Boundary{Number: 3, Point: *b.startFinish})
+		}
+	}
+
+	return result
+}
+
+func (b *Builder) driver(driverNumber int) *driverTrace {
+	driver, exists := b.drivers[driverNumber]
+	if !exists {
+		driver = &driverTrace{}
+		b.drivers[driverNumber] = driver
+	}
+	return driver
+}
+
+// Handle the timing changes we now have locations for
+func (b *Builder) processEvents(driver *driverTrace) {
+	latest := driver.locations[len(driver.locations)-1].Timestamp
+
+	for len(driver.events) > 0 && !driver.events[0].timestamp.After(latest) {
+		event := driver.events[0]
+		driver.events = driver.events[1:]
+
+		point, found := driver.locationAt(event.timestamp)
+
+		switch event.eventType {
+		case lapEvent:
+			if found && b.startFinish == nil {
+				b.startFinish = &point
+			}
+
+			if len(b.outline) == 0 && driver.cleanLap && !driver.lapStart.IsZero() {
+				b.outline = driver.path(driver.lapStart, event.timestamp, true)
+			}
+
+			driver.lapStart = event.timestamp
+			driver.cleanLap = !driver.inPit && driver.pitStart.IsZero()
+
+		case segmentEvent:
+			if _, exists := b.segments[event.segment]; !exists && found {
+				b.segments[event.segment] = point
+			}
+
+		case pitEntryEvent:
+			driver.pitStart = event.timestamp
+			driver.cleanLap = false
+
+		case pitExitEvent:
+			if len(b.pitLane) == 0 && !driver.pitStart.IsZero() {
+				b.pitLane = driver.path(driver.pitStart, event.timestamp, false)
+			}
+			driver.pitStart = time.Time{}
+		}
+	}
+}
+
+// Locations between the two times, empty if there are gaps in the data or if requested the car went off track
+func (d *driverTrace) path(start time.Time, end time.Time, onTrack bool) []Point {
+	result := make([]Point, 0)
+	var previous time.Time
+
+	for _, location := range d.locations {
+		if location.Timestamp.Before(start) || location.Timestamp.After(end) {
+			continue
+		}
+
+		if location.Status == Messages.PositionNoFix ||
+			(onTrack && location.Status == Messages.PositionOffTrack) ||
+			(!previous.IsZero() && location.Timestamp.Sub(previous) > maxLocationGap) {
+			return nil
+		}
+		previous = location.Timestamp
+
+		result = append(result, Point{X: location.X, Y: location.Y, Z: location.Z})
+	}
+
+	if len(result) < minOutlineLocations {
+		return nil
+	}
+
+	return result
+}
+
+// Where the car was at the time, interpolated between the locations either side
+func (d *driverTrace) locationAt(timestamp time.Time) (Point, bool) {
+	for x := 1; x < len(d.locations); x++ {
+		after := d.locations[x]
+		if after.Timestamp.Before(timestamp) {
+			continue
+		}
+
+		before := d.locations[x-1]
+		if before.Timestamp.After(timestamp) ||
+			before.Status == Messages.PositionNoFix ||
+			after.Status == Messages.PositionNoFix ||
+			after.Timestamp.Sub(before.Timestamp) > maxLocationGap {
+			return Point{}, false
+		}
+
+		fraction := float64(timestamp.Sub(before.Timestamp)) / float64(after.Timestamp.Sub(before.Timestamp))
+		return Point{
+			X: before.X + (after.X-before.X)*fraction,
+			Y: before.Y + (after.Y-before.Y)*fraction,
+			Z: before.Z + (after.Z-before.Z)*fraction,
+		}, true
+	}
+
+	return Point{}, false
+}
+
+// Drop locations we won't need again
+func (d *driverTrace) trim() {
+	oldest := d.locations[len(d.locations)-1].Timestamp.Add(-maxHistory)
+	keepFrom := oldest
+	if !d.lapStart.IsZero() && d.lapStart.After(keepFrom) {
+		keepFrom = d.lapStart
+	}
+	if !d.pitStart.IsZero() {
+		// A car in the pits for longer than we keep history for is in the garage, not driving down the pit lane
+		if d.pitStart.Before(oldest) {
+			d.pitStart = time.Time{}
+		} else if d.pitStart.Before(keepFrom) {
+			keepFrom = d.pitStart
+		}
+	}
+
+	// Keep one before so we can still interpolate at the start time
+	x := 0
+	for x < len(d.locations)-1 && d.locations[x+1].Timestamp.Before(keepFrom) {
+		x++
+	}
+	d.locations = d.locations[x:]
+}
@@ -0,0 +1,223 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package trackMap
+
+import (
+	"math"
+	"sort"
+)
+
+// Locations are sent in tenths of a metre
+const UnitsPerMetre = 10.0
+
+// How far in metres either side of the previous distance to look for the nearest point on the outline
+const nearSearchDistance = 300.0
+
+// A car further than this in metres from the outline near its previous distance has been somewhere else
+const nearMaxOffset = 50.0
+
+// Works out how far around the lap a location is from the track outline
+type LapDistance struct {
+	outline []Point
+	// Metres from the start of the outline to each point
+	distance []float64
+	length   float64
+}
+
+func CreateLapDistance(outline []Point) *LapDistance {
+	if len(outline) < 2 {
+		return nil
+	}
+
+	result := LapDistance{
+		outline:  outline,
+		distance: make([]float64, len(outline)),
+	}
+
+	for x := 1; x < len(outline); x++ {
+		result.distance[x] = result.distance[x-1] + metresBetween(outline[x-1], outline[x])
+	}
+
+	// The outline is a loop so the last point joins back up with the first
+	result.length = result.distance[len(outline)-1] + metresBetween(outline[len(outline)-1], outline[0])
+
+	return &result
+}
+
+// Length of the lap in metres
+func (l *LapDistance) Length() float64 {
+	return l.length
+}
+
+// Distance in metres around the lap and the fraction of the lap for the nearest point on the outline
+func (l *LapDistance) Distance(point Point) (float64, float64) {
+	closest := math.MaxFloat64
+	var result float64
+
+	for x := range l.outline {
+		distanceSquared, distance := l.nearest(x, point)
+		if distanceSquared < closest {
+			closest = distanceSquared
+			result = distance
+		}
+	}
+
+	return result, result / l.length
+}
+
+// Distance in metres around the lap and the fraction of the lap for the nearest point on the outline close to
+// where the car was before. Where the track crosses over itself the nearest point on the whole outline can be
+// on the wrong part of the lap. Falls back to the whole outline when the car isn't near the previous distance.
+func (l *LapDistance) DistanceNear(point Point, previous float64) (float64, float64) {
+	previous, _ = l.Wrap(previous)
+
+	// The outline section the previous distance is on
+	start := sort.SearchFloat64s(l.distance, previous)
+	if start == len(l.distance) || (start > 0 && l.distance[start] > previous) {
+		start--
+	}
+
+	closest := math.MaxFloat64
+	var result float64
+
+	// Search forwards and backwards from there until the sections are too far away
+	for _, direction := range []int{1, -1} {
+		for step := 0; step < len(l.outline); step++ {
+			x := ((start+direction*step)%len(l.outline) + len(l.outline)) % len(l.outline)
+			if step > 0 && l.lapsApart(l.distance[x], previous) > nearSearchDistance {
+				break
+			}
+
+			distanceSquared, distance := l.nearest(x, point)
+			if distanceSquared < closest {
+				closest = distanceSquared
+				result = distance
+			}
+		}
+	}
+
+	if math.Sqrt(closest)/UnitsPerMetre > nearMaxOffset {
+		return l.Distance(point)
+	}
+
+	return result, result / l.length
+}
+
+// How far apart two distances are going either way around the lap
+func (l *LapDistance) lapsApart(a float64, b float64) float64 {
+	apart := math.Abs(a - b)
+	return math.Min(apart, l.length-apart)
+}
+
+// The squared distance to the nearest point on the outline section starting at the index and the lap distance
+// of that point
+func (l *LapDistance) nearest(x int, point Point) (float64, float64) {
+	start := l.outline[x]
+	end := l.outline[(x+1)%len(l.outline)]
+
+	// How far along the line between the two points is closest to the point
+	dx, dy, dz := end.X-start.X, end.Y-start.Y, end.Z-start.Z
+	lengthSquared := dx*dx + dy*dy + dz*dz
+	fraction := 0.0
+	if lengthSquared > 0 {
+		fraction = ((point.X-start.X)*dx + (point.Y-start.Y)*dy + (point.Z-start.Z)*dz) / lengthSquared
+		fraction = math.Max(0, math.Min(1, fraction))
+	}
+
+	nearest := Point{X: start.X + dx*fraction, Y: start.Y + dy*fraction, Z: start.Z + dz*fraction}
+	distanceSquared := squared(point.X-nearest.X) + squared(point.Y-nearest.Y) + squared(point.Z-nearest.Z)
+
+	return distanceSquared, l.distance[x] + fraction*math.Sqrt(lengthSquared)/UnitsPerMetre
+}
+
+// Keeps a distance within a single lap
+func (l *LapDistance) Wrap(distance float64) (float64, float64) {
+	distance = math.Mod(distance, l.length)
+	if distance < 0 {
+		distance += l.length
+	}
+	return distance, distance / l.length
+}
+
+// Works out how far around the lap a car in the pit lane is from how far along the pit lane it is. The pit lane
+// doesn't follow the track so the nearest point on the outline can jump around or be on another part of the lap.
+type PitLaneDistance struct {
+	pitLane *LapDistance
+	// Length of the pit lane in metres
+	pitLaneLength float64
+
+	lap *LapDistance
+	// Lap distance of the pit entry and how far around the lap it is to the pit exit
+	entry float64
+	span  float64
+}
+
+func CreatePitLaneDistance(lap *LapDistance, pitLane []Point) *PitLaneDistance {
+	if lap == nil || len(pitLane) < 2 {
+		return nil
+	}
+
+	result := PitLaneDistance{
+		pitLane: CreateLapDistance(pitLane),
+		lap:     lap,
+	}
+	result.pitLaneLength = result.pitLane.distance[len(pitLane)-1]
+	if result.pitLaneLength <= 0 {
+		return nil
+	}
+
+	// The pit lane usually crosses the line so the exit can be a smaller distance than the entry
+	var exit float64
+	result.entry, _ = lap.Distance(pitLane[0])
+	exit, _ = lap.Distance(pitLane[len(pitLane)-1])
+	result.span = exit - result.entry
+	if result.span < 0 {
+		result.span += lap.length
+	}
+
+	return &result
+}
+
+// Distance in metres around the lap and the fraction of the lap for a point in the pit lane, false if the point
+// isn't near the pit lane
+func (p *PitLaneDistance) Distance(point Point) (float64, float64, bool) {
+	closest := math.MaxFloat64
+	var along float64
+
+	// The pit lane isn't a loop so don't join the exit back up with the entry
+	for x := 0; x < len(p.pitLane.outline)-1; x++ {
+		distanceSquared, distance := p.pitLane.nearest(x, point)
+		if distanceSquared < closest {
+			closest = distanceSquared
+			along = distance
+		}
+	}
+
+	if math.Sqrt(closest)/UnitsPerMetre > nearMaxOffset {
+		return 0, 0, false
+	}
+
+	distance, fraction := p.lap.Wrap(p.entry + p.span*along/p.pitLaneLength)
+	return distance, fraction, true
+}
+
+func metresBetween(a Point, b Point) float64 {
+	return math.Sqrt(squared(b.X-a.X)+squared(b.Y-a.Y)+squared(b.Z-a.Z)) / UnitsPerMetre
+}
+
+func squared(value float64) float64 {
+	return value * value
+}
@@ -0,0 +1,93 @@
+// F1GopherLib - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package trackMap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type Point struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Where a sector or segment ends, numbered from 1
+type Boundary struct {
+	Number int
+	Point  Point
+}
+
+type TrackMap struct {
+	Track string
+	Year  int
+
+	// The racing line for a clean lap starting and ending at the start/finish line
+	Outline []Point
+	// From the pit entry to the pit exit
+	PitLane     []Point
+	StartFinish Point
+
+	Sectors       []Boundary
+	Segments      []Boundary
+	TotalSegments int
+
+	// Where each marshal sector ends, numbered as race control does. These aren't in the feed so a built map has one
+	// for each segment, they can be corrected in the saved map for tracks where they are different
+	MarshalSectors []Boundary
+}
+
+// Has everything been found for the track
+func (t TrackMap) Complete() bool {
+	return len(t.Outline) > 0 &&
+		len(t.PitLane) > 0 &&
+		len(t.Sectors) == 3 &&
+		t.TotalSegments > 0 &&
+		len(t.Segments) == t.TotalSegments
+}
+
+func Load(folder string, track string, year int) (TrackMap, error) {
+	var result TrackMap
+
+	data, err := os.ReadFile(fileName(folder, track, year))
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+func (t TrackMap) Save(folder string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(folder, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fileName(folder, t.Track, t.Year), data, 0644)
+}
+
+func fileName(folder string, track string, year int) string {
+	return filepath.Join(folder, fmt.Sprintf("%s_%d.json", track, year))
+}